@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+)
+
+func main() {
+	sealPlaintext := flag.Bool("seal-plaintext", false, "also encrypt messages and replies stored before encryption at rest was enabled")
+	flag.Parse()
+
+	repositories.ConnectDatabase()
+
+	keyring, err := repositories.LoadKeyring()
+	if err != nil {
+		log.Fatalf("Invalid master keys: %v", err)
+	}
+	if !keyring.Enabled() {
+		log.Fatal("No master keys configured, set MASTER_KEYS")
+	}
+
+	rotated, err := repositories.RotateDataKeys(keyring)
+	if err != nil {
+		log.Fatalf("Rotation failed after %d keys: %v", rotated, err)
+	}
+	log.Printf("Re-wrapped %d data keys with master key %s", rotated, keyring.CurrentID())
+
+	if *sealPlaintext {
+		sealed, err := repositories.SealPlaintextMessages()
+		if err != nil {
+			log.Fatalf("Sealing failed after %d messages and replies: %v", sealed, err)
+		}
+		log.Printf("Encrypted %d plaintext messages and replies", sealed)
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/rohits-web03/SilentEcho/server/internal/api"
 	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
 	"github.com/rohits-web03/SilentEcho/server/internal/queue"
 	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
 )
@@ -23,8 +24,22 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to declare queue: %v", err)
 	}
+
+	// Live inbox updates reach every instance through the events exchange
+	if err := rmq.DeclareExchange(config.Envs.EVENTS_EXCHANGE, "topic"); err != nil {
+		log.Fatalf("Failed to declare exchange: %v", err)
+	}
+	hub := events.NewHub()
+	go hub.Run(rmq, config.Envs.EVENTS_EXCHANGE, "message.#")
+
+	// Note attachments
+	store, err := repositories.OpenBlobStore()
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+
 	// Setup Gin router
-	r := api.SetupRouter(rmq)
+	r := api.SetupRouter(rmq, hub, store)
 
 	port := config.Envs.Port
 	if port == "" {
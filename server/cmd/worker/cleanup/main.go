@@ -0,0 +1,35 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+)
+
+// cleanupInterval is how often attachments of dead notes are looked for
+const cleanupInterval = time.Minute
+
+func main() {
+	repositories.ConnectDatabase()
+
+	store, err := repositories.OpenBlobStore()
+	if err != nil {
+		log.Fatalf("Failed to open blob store: %v", err)
+	}
+
+	log.Println(" [*] Purging attachments of burned and expired notes. To exit press CTRL+C")
+
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		purged, err := worker.PurgeAttachments(store, now)
+		if err != nil {
+			log.Printf("Failed to purge attachments: %v", err)
+		}
+		if purged > 0 {
+			log.Printf("Purged %d attachments", purged)
+		}
+	}
+}
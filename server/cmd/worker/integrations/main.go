@@ -0,0 +1,63 @@
+package main
+
+import (
+	"log"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+)
+
+func main() {
+	repositories.ConnectDatabase()
+
+	rmq, err := queue.NewRabbitMQ(config.Envs.MQ_URL)
+	if err != nil {
+		log.Fatalf("Failed to connect RabbitMQ: %v", err)
+	}
+	defer rmq.Close()
+
+	if err := rmq.DeclareExchange(config.Envs.EVENTS_EXCHANGE, "topic"); err != nil {
+		log.Fatalf("Failed to declare exchange: %v", err)
+	}
+	if _, err := rmq.DeclareQueue(config.Envs.INTEGRATIONS_EVENTS_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.BindQueue(config.Envs.INTEGRATIONS_EVENTS_QUEUE, config.Envs.EVENTS_EXCHANGE, events.MessageCreated); err != nil {
+		log.Fatalf("Failed to bind queue: %v", err)
+	}
+	if _, err := rmq.DeclareQueue(config.Envs.INTEGRATIONS_DELIVERY_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.DeclareDelayQueues(config.Envs.INTEGRATIONS_RETRY_QUEUE, config.Envs.INTEGRATIONS_DELIVERY_QUEUE, worker.IntegrationRetryDelays()); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+
+	eventMsgs, eventCh, err := rmq.Consume(config.Envs.INTEGRATIONS_EVENTS_QUEUE)
+	if err != nil {
+		log.Fatalf("Failed to register consumer: %v", err)
+	}
+	defer eventCh.Close()
+
+	jobMsgs, jobCh, err := rmq.Consume(config.Envs.INTEGRATIONS_DELIVERY_QUEUE)
+	if err != nil {
+		log.Fatalf("Failed to register consumer: %v", err)
+	}
+	defer jobCh.Close()
+
+	log.Println(" [*] Waiting for messages to forward. To exit press CTRL+C")
+
+	go func() {
+		for d := range eventMsgs {
+			worker.ProcessIntegrationEvent(rmq, d)
+		}
+	}()
+
+	client := webhook.NewClient(config.Envs.AllowPrivateWebhooks)
+	for d := range jobMsgs {
+		worker.ProcessIntegrationJob(rmq, client, d)
+	}
+}
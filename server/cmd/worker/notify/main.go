@@ -0,0 +1,68 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/notify"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+)
+
+// digestInterval is how often due digests are looked for
+const digestInterval = time.Minute
+
+func main() {
+	repositories.ConnectDatabase()
+
+	rmq, err := queue.NewRabbitMQ(config.Envs.MQ_URL)
+	if err != nil {
+		log.Fatalf("Failed to connect RabbitMQ: %v", err)
+	}
+	defer rmq.Close()
+
+	if _, err := rmq.DeclareQueue(config.Envs.EMAIL_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.DeclareExchange(config.Envs.EVENTS_EXCHANGE, "topic"); err != nil {
+		log.Fatalf("Failed to declare exchange: %v", err)
+	}
+	if _, err := rmq.DeclareQueue(config.Envs.NOTIFY_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.BindQueue(config.Envs.NOTIFY_QUEUE, config.Envs.EVENTS_EXCHANGE, events.MessageCreated, events.NoteFirstViewed); err != nil {
+		log.Fatalf("Failed to bind queue: %v", err)
+	}
+
+	msgs, ch, err := rmq.Consume(config.Envs.NOTIFY_QUEUE)
+	if err != nil {
+		log.Fatalf("Failed to register consumer: %v", err)
+	}
+	defer ch.Close()
+
+	log.Println(" [*] Waiting for message and note events and digests. To exit press CTRL+C")
+
+	go func() {
+		for d := range msgs {
+			switch d.RoutingKey {
+			case events.NoteFirstViewed:
+				worker.ProcessNoteViewEvent(rmq, d)
+			default:
+				worker.ProcessMessageEvent(rmq, d)
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(digestInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		for _, mode := range []string{notify.ModeHourly, notify.ModeDaily} {
+			if err := worker.SendDigests(rmq, mode, now); err != nil {
+				log.Printf("Failed to send %s digests: %v", mode, err)
+			}
+		}
+	}
+}
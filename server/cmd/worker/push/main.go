@@ -0,0 +1,51 @@
+package main
+
+import (
+	"log"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+	"github.com/rohits-web03/SilentEcho/server/internal/webpush"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+)
+
+func main() {
+	vapid, err := webpush.NewVAPID(config.Envs.VAPIDPublicKey, config.Envs.VAPIDPrivateKey, config.Envs.VAPIDSubject)
+	if err != nil {
+		log.Fatalf("Push notifications need VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY: %v", err)
+	}
+
+	repositories.ConnectDatabase()
+
+	rmq, err := queue.NewRabbitMQ(config.Envs.MQ_URL)
+	if err != nil {
+		log.Fatalf("Failed to connect RabbitMQ: %v", err)
+	}
+	defer rmq.Close()
+
+	if err := rmq.DeclareExchange(config.Envs.EVENTS_EXCHANGE, "topic"); err != nil {
+		log.Fatalf("Failed to declare exchange: %v", err)
+	}
+	if _, err := rmq.DeclareQueue(config.Envs.PUSH_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.BindQueue(config.Envs.PUSH_QUEUE, config.Envs.EVENTS_EXCHANGE, events.MessageCreated); err != nil {
+		log.Fatalf("Failed to bind queue: %v", err)
+	}
+
+	msgs, ch, err := rmq.Consume(config.Envs.PUSH_QUEUE)
+	if err != nil {
+		log.Fatalf("Failed to register consumer: %v", err)
+	}
+	defer ch.Close()
+
+	log.Println(" [*] Waiting for messages to push. To exit press CTRL+C")
+
+	client := webhook.NewClient(config.Envs.AllowPrivateWebhooks)
+	for d := range msgs {
+		worker.ProcessPushEvent(client, vapid, d)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+)
+
+// sweepInterval is how often lost deliveries, old deliveries and expired
+// notes are looked for
+const sweepInterval = time.Minute
+
+func main() {
+	repositories.ConnectDatabase()
+
+	rmq, err := queue.NewRabbitMQ(config.Envs.MQ_URL)
+	if err != nil {
+		log.Fatalf("Failed to connect RabbitMQ: %v", err)
+	}
+	defer rmq.Close()
+
+	if err := rmq.DeclareExchange(config.Envs.EVENTS_EXCHANGE, "topic"); err != nil {
+		log.Fatalf("Failed to declare exchange: %v", err)
+	}
+	if _, err := rmq.DeclareQueue(config.Envs.WEBHOOK_EVENTS_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.BindQueue(config.Envs.WEBHOOK_EVENTS_QUEUE, config.Envs.EVENTS_EXCHANGE, events.WebhookTypes...); err != nil {
+		log.Fatalf("Failed to bind queue: %v", err)
+	}
+	if _, err := rmq.DeclareQueue(config.Envs.WEBHOOK_DELIVERY_QUEUE); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+	if err := rmq.DeclareDelayQueues(config.Envs.WEBHOOK_RETRY_QUEUE, config.Envs.WEBHOOK_DELIVERY_QUEUE, worker.WebhookRetryDelays()); err != nil {
+		log.Fatalf("Failed to declare queue: %v", err)
+	}
+
+	eventMsgs, eventCh, err := rmq.Consume(config.Envs.WEBHOOK_EVENTS_QUEUE)
+	if err != nil {
+		log.Fatalf("Failed to register consumer: %v", err)
+	}
+	defer eventCh.Close()
+
+	deliveryMsgs, deliveryCh, err := rmq.Consume(config.Envs.WEBHOOK_DELIVERY_QUEUE)
+	if err != nil {
+		log.Fatalf("Failed to register consumer: %v", err)
+	}
+	defer deliveryCh.Close()
+
+	log.Println(" [*] Waiting for webhook events. To exit press CTRL+C")
+
+	go func() {
+		for d := range eventMsgs {
+			worker.ProcessWebhookEvent(rmq, d)
+		}
+	}()
+
+	client := webhook.NewClient(config.Envs.AllowPrivateWebhooks)
+	go func() {
+		for d := range deliveryMsgs {
+			worker.ProcessDelivery(rmq, client, d)
+		}
+	}()
+
+	publisher := events.NewPublisher(rmq, config.Envs.EVENTS_EXCHANGE)
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		if err := worker.RequeueStaleDeliveries(rmq, now); err != nil {
+			log.Printf("Failed to requeue stale deliveries: %v", err)
+		}
+		if _, err := worker.PruneWebhookDeliveries(now); err != nil {
+			log.Printf("Failed to prune webhook deliveries: %v", err)
+		}
+		if err := worker.AnnounceExpiredNotes(publisher, now); err != nil {
+			log.Printf("Failed to announce expired notes: %v", err)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/attachments"
+	"github.com/rohits-web03/SilentEcho/server/internal/blobstore"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxAttachmentChunks keeps clients from splitting a file into tiny pieces
+const maxAttachmentChunks = 1000
+
+// downloadKey signs attachment download links
+var downloadKey = config.TokenKey("attachment download")
+
+var (
+	errAttachmentLimit = errors.New("attachment limit reached")
+	errAttachmentQuota = errors.New("attachment quota exceeded")
+)
+
+type AttachmentHandler struct {
+	store blobstore.Store
+}
+
+func NewAttachmentHandler(store blobstore.Store) *AttachmentHandler {
+	return &AttachmentHandler{store: store}
+}
+
+// GET /api/notes/:slug/attachments
+func GetAttachments(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	note, ok := findOwnedNote(c, userID)
+	if !ok {
+		return
+	}
+
+	var list []models.Attachment
+	if err := repositories.DB.Order("created_at").Where("note_id = ?", note.ID).Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch attachments"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Attachments fetched successfully", "data": list})
+}
+
+// POST /api/notes/:slug/attachments
+// Starts an upload, the chunks follow with PUT .../chunks/:index
+func CreateAttachment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		EncryptedName string `json:"encryptedName"`
+		Size          int64  `json:"size"`
+		ChunkCount    int    `json:"chunkCount"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	note, ok := findOwnedNote(c, userID)
+	if !ok {
+		return
+	}
+	if !note.Live(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Attachments can only be added to notes that can still be read"})
+		return
+	}
+
+	maxSize := config.Envs.AttachmentMaxSize
+	chunkSize := config.Envs.AttachmentChunkSize
+	if input.Size < 1 || input.Size > maxSize {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": fmt.Sprintf("Attachments must be between 1 and %d bytes", maxSize)})
+		return
+	}
+	minChunks := int((input.Size + chunkSize - 1) / chunkSize)
+	if input.ChunkCount < minChunks || input.ChunkCount > maxAttachmentChunks || int64(input.ChunkCount) > input.Size {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": fmt.Sprintf("Chunks may be at most %d bytes each", chunkSize)})
+		return
+	}
+
+	attachment := models.Attachment{
+		NoteID:        &note.ID,
+		EncryptedName: input.EncryptedName,
+		Size:          input.Size,
+		ChunkCount:    input.ChunkCount,
+		Status:        models.AttachmentUploading,
+	}
+	err := repositories.DB.Transaction(func(tx *gorm.DB) error {
+		// Locking the owner makes concurrent uploads take turns, so none of
+		// them is checked against totals another is about to change
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where("id = ?", note.UserID).First(&models.User{}).Error; err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Attachment{}).Where("note_id = ?", note.ID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(config.Envs.AttachmentsPerNote) {
+			return errAttachmentLimit
+		}
+
+		var used int64
+		err := tx.Model(&models.Attachment{}).
+			Joins("JOIN notes ON notes.id = attachments.note_id").
+			Where("notes.user_id = ?", note.UserID).
+			Select("COALESCE(SUM(attachments.size), 0)").Scan(&used).Error
+		if err != nil {
+			return err
+		}
+		if used+input.Size > config.Envs.UserAttachmentQuota {
+			return errAttachmentQuota
+		}
+
+		return tx.Create(&attachment).Error
+	})
+	switch {
+	case errors.Is(err, errAttachmentLimit):
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Attachment limit reached for this note"})
+		return
+	case errors.Is(err, errAttachmentQuota):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"success": false, "message": "Attachment storage quota exceeded"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create attachment"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Attachment created, upload its chunks next", "data": gin.H{
+		"attachment":   attachment,
+		"maxChunkSize": chunkSize,
+	}})
+}
+
+// PUT /api/notes/:slug/attachments/:attachmentId/chunks/:index
+// The body is the raw encrypted chunk, re-uploading an index replaces it
+func (h *AttachmentHandler) UploadChunk(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	attachment, ok := findOwnedAttachment(c, userID)
+	if !ok {
+		return
+	}
+	if attachment.Status != models.AttachmentUploading {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Attachment upload is already complete"})
+		return
+	}
+
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 || index >= attachment.ChunkCount {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid chunk index"})
+		return
+	}
+
+	chunkSize := config.Envs.AttachmentChunkSize
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, chunkSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Failed to read chunk"})
+		return
+	}
+	if int64(len(body)) > chunkSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"success": false, "message": fmt.Sprintf("Chunks may be at most %d bytes", chunkSize)})
+		return
+	}
+	if len(body) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Chunk is empty"})
+		return
+	}
+
+	var others int64
+	repositories.DB.Model(&models.AttachmentChunk{}).
+		Where("attachment_id = ? AND position <> ?", attachment.ID, index).
+		Select("COALESCE(SUM(size), 0)").Scan(&others)
+	if others+int64(len(body)) > attachment.Size {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Chunks exceed the declared attachment size"})
+		return
+	}
+
+	if err := h.store.Put(c.Request.Context(), attachments.ChunkKey(attachment.ID, index), bytes.NewReader(body), int64(len(body))); err != nil {
+		log.Printf("Failed to store chunk %d of attachment %s: %v\n", index, attachment.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to store chunk"})
+		return
+	}
+
+	chunk := models.AttachmentChunk{AttachmentID: attachment.ID, Index: index, Size: int64(len(body))}
+	err = repositories.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "attachment_id"}, {Name: "position"}},
+		DoUpdates: clause.AssignmentColumns([]string{"size"}),
+	}).Create(&chunk).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to store chunk"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Chunk uploaded", "data": chunk})
+}
+
+// POST /api/notes/:slug/attachments/:attachmentId/complete
+func CompleteAttachment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	attachment, ok := findOwnedAttachment(c, userID)
+	if !ok {
+		return
+	}
+	if attachment.Status == models.AttachmentComplete {
+		c.JSON(http.StatusOK, gin.H{"success": true, "message": "Attachment is complete", "data": attachment})
+		return
+	}
+
+	var uploaded struct {
+		Count int
+		Total int64
+	}
+	repositories.DB.Model(&models.AttachmentChunk{}).
+		Where("attachment_id = ?", attachment.ID).
+		Select("COUNT(*) AS count, COALESCE(SUM(size), 0) AS total").Scan(&uploaded)
+	if uploaded.Count != attachment.ChunkCount || uploaded.Total != attachment.Size {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Upload is incomplete", "data": gin.H{
+			"chunksUploaded": uploaded.Count,
+			"bytesUploaded":  uploaded.Total,
+		}})
+		return
+	}
+
+	now := time.Now()
+	attachment.Status = models.AttachmentComplete
+	attachment.CompletedAt = &now
+	if err := repositories.DB.Save(&attachment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to complete attachment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Attachment is complete", "data": attachment})
+}
+
+// DELETE /api/notes/:slug/attachments/:attachmentId
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	attachment, ok := findOwnedAttachment(c, userID)
+	if !ok {
+		return
+	}
+
+	if err := attachments.DeleteChunks(c.Request.Context(), h.store, attachment.ID, attachment.ChunkCount); err != nil {
+		log.Printf("Failed to delete chunks of attachment %s: %v\n", attachment.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete attachment"})
+		return
+	}
+	if err := repositories.DB.Delete(&attachment).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete attachment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Attachment deleted successfully"})
+}
+
+// GET /api/notes/:slug/attachments/:attachmentId?token=...
+// The token comes with the note, so only someone who just read it can download
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachmentID, err := uuid.Parse(c.Param("attachmentId"))
+	if err != nil || attachments.VerifyDownloadToken(downloadKey, c.Query("token"), attachmentID, time.Now()) != nil {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Download link is invalid or has expired"})
+		return
+	}
+
+	var attachment models.Attachment
+	err = repositories.DB.Preload("Note").
+		Where("id = ? AND status = ? AND note_id IS NOT NULL", attachmentID, models.AttachmentComplete).
+		First(&attachment).Error
+	if err != nil || attachment.Note.Slug != c.Param("slug") {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Attachment not found"})
+		return
+	}
+
+	// Used up views are fine, the token proves the note was read in time
+	if status := attachment.Note.Status(time.Now()); status == models.NoteRevoked || status == models.NoteExpired || status == models.NoteDestroyed {
+		noteUnavailable(c, status)
+		return
+	}
+
+	c.Header("Content-Type", "application/octet-stream")
+	c.Header("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.bin"`, attachment.ID))
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+
+	// Chunks are streamed one after another, never held in memory together
+	for i := 0; i < attachment.ChunkCount; i++ {
+		chunk, err := h.store.Get(c.Request.Context(), attachments.ChunkKey(attachment.ID, i))
+		if err != nil {
+			log.Printf("Failed to read chunk %d of attachment %s: %v\n", i, attachment.ID, err)
+			c.Abort()
+			return
+		}
+		_, err = io.Copy(c.Writer, chunk)
+		chunk.Close()
+		if err != nil {
+			c.Abort()
+			return
+		}
+	}
+}
+
+// noteAttachments lists the complete attachments of a note with download links
+func noteAttachments(n models.Note) []gin.H {
+	var list []models.Attachment
+	repositories.DB.Order("created_at").Where("note_id = ? AND status = ?", n.ID, models.AttachmentComplete).Find(&list)
+
+	expires := time.Now().Add(config.Envs.AttachmentDownloadTTL)
+	out := make([]gin.H, 0, len(list))
+	for _, a := range list {
+		token := attachments.DownloadToken(downloadKey, a.ID, expires)
+		out = append(out, gin.H{
+			"id":            a.ID,
+			"encryptedName": a.EncryptedName,
+			"size":          a.Size,
+			"downloadUrl":   fmt.Sprintf("%s/api/notes/%s/attachments/%s?token=%s", config.Envs.APIURL, n.Slug, a.ID, token),
+			"expiresAt":     expires,
+		})
+	}
+	return out
+}
+
+// findOwnedNote loads the :slug note of the user, writing the error response if missing
+func findOwnedNote(c *gin.Context, userID interface{}) (models.Note, bool) {
+	var note models.Note
+	if err := repositories.DB.Where("slug = ? AND user_id = ?", c.Param("slug"), userID).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch note"})
+		}
+		return note, false
+	}
+	return note, true
+}
+
+// findOwnedAttachment loads :attachmentId of the user's :slug note
+func findOwnedAttachment(c *gin.Context, userID interface{}) (models.Attachment, bool) {
+	var attachment models.Attachment
+
+	note, ok := findOwnedNote(c, userID)
+	if !ok {
+		return attachment, false
+	}
+
+	if err := repositories.DB.Where("id = ? AND note_id = ?", c.Param("attachmentId"), note.ID).First(&attachment).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Attachment not found"})
+		return attachment, false
+	}
+	return attachment, true
+}
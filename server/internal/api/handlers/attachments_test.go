@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+)
+
+// createAttachments starts n uploads to note at once and returns their status codes
+func createAttachments(owner models.User, note models.Note, n int, size int64) []int {
+	r := gin.New()
+	r.POST("/notes/:slug/attachments", asUser(owner), CreateAttachment)
+	chunks := (size + config.Envs.AttachmentChunkSize - 1) / config.Envs.AttachmentChunkSize
+	body, _ := json.Marshal(gin.H{"encryptedName": "bmFtZQ==", "size": size, "chunkCount": chunks})
+
+	codes := make([]int, n)
+	var wg sync.WaitGroup
+	for i := range codes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPost, "/notes/"+note.Slug+"/attachments", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			codes[i] = w.Code
+		}()
+	}
+	wg.Wait()
+	return codes
+}
+
+func countCodes(codes []int, code int) int {
+	n := 0
+	for _, c := range codes {
+		if c == code {
+			n++
+		}
+	}
+	return n
+}
+
+func TestCreateAttachmentLimitsConcurrentUploads(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner)
+
+	codes := createAttachments(owner, note, 12, 1)
+	if got, want := countCodes(codes, http.StatusCreated), config.Envs.AttachmentsPerNote; got != want {
+		t.Errorf("%d uploads started, the limit is %d: %v", got, want, codes)
+	}
+	var stored int64
+	repositories.DB.Model(&models.Attachment{}).Where("note_id = ?", note.ID).Count(&stored)
+	if stored != int64(config.Envs.AttachmentsPerNote) {
+		t.Errorf("%d attachments stored", stored)
+	}
+}
+
+func TestCreateAttachmentKeepsQuotaUnderConcurrentUploads(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	notes := []models.Note{createTestNote(t, owner), createTestNote(t, owner), createTestNote(t, owner)}
+
+	// Each upload fits the quota alone, any two together do not
+	size := config.Envs.UserAttachmentQuota/2 + 1
+	if size > config.Envs.AttachmentMaxSize {
+		t.Skip("quota is more than twice the attachment size limit")
+	}
+
+	var codes []int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, n := range notes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			got := createAttachments(owner, n, 1, size)
+			mu.Lock()
+			codes = append(codes, got...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if got := countCodes(codes, http.StatusCreated); got != 1 {
+		t.Errorf("%d uploads fit a quota with room for one: %v", got, codes)
+	}
+}
@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
+	"gorm.io/gorm"
+)
+
+const maxBlockedWordLength = 64
+
+// senderFingerprint identifies the sender of the current request for the given recipient
+func senderFingerprint(c *gin.Context, recipient models.User) string {
+	return utils.SenderFingerprint(config.Envs.FingerprintSalt, recipient.ID.String(), c.ClientIP(), c.Request.UserAgent())
+}
+
+// isBlockedSubmission reports whether the recipient has blocked this sender or
+// one of the words in content. Hits are counted so the owner can see them.
+func isBlockedSubmission(recipient models.User, fingerprint, content string) bool {
+	var sender models.BlockedSender
+	err := repositories.DB.Where("user_id = ? AND fingerprint = ?", recipient.ID, fingerprint).First(&sender).Error
+	if err == nil {
+		countBlocked(recipient, &sender)
+		return true
+	}
+
+	// Keywords cannot match content the server is unable to read
+	if content == "" {
+		return false
+	}
+
+	var words []models.BlockedWord
+	if err := repositories.DB.Where("user_id = ?", recipient.ID).Find(&words).Error; err != nil {
+		log.Printf("Error loading blocked words for user %s: %v\n", recipient.ID, err)
+		return false
+	}
+
+	keywords := make([]string, len(words))
+	for i, w := range words {
+		keywords[i] = w.Word
+	}
+	match, ok := moderation.MatchKeyword(content, keywords)
+	if !ok {
+		return false
+	}
+	for i := range words {
+		if words[i].Word == match {
+			countBlocked(recipient, &words[i])
+			break
+		}
+	}
+	return true
+}
+
+func countBlocked(recipient models.User, entry interface{}) {
+	err := repositories.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(entry).UpdateColumn("hit_count", gorm.Expr("hit_count + 1")).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", recipient.ID).
+			UpdateColumn("dropped_messages", gorm.Expr("dropped_messages + 1")).Error
+	})
+	if err != nil {
+		log.Printf("Error counting blocked message for user %s: %v\n", recipient.ID, err)
+	}
+}
+
+// GET /api/user/blocked-words
+func GetBlockedWords(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var words []models.BlockedWord
+	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&words).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch blocked words"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Blocked words fetched successfully", "data": words})
+}
+
+// POST /api/user/blocked-words
+func AddBlockedWord(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Word string `json:"word"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	word := strings.ToLower(strings.TrimSpace(input.Word))
+	if len(moderation.Tokenize(word)) == 0 || utf8.RuneCountInString(word) > maxBlockedWordLength {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Word must be between 1 and 64 characters"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	blocked := models.BlockedWord{UserID: uid, Word: word}
+	result := repositories.DB.Where(models.BlockedWord{UserID: uid, Word: word}).FirstOrCreate(&blocked)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to block word"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Word blocked", "data": blocked})
+}
+
+// DELETE /api/user/blocked-words/:id
+func DeleteBlockedWord(c *gin.Context) {
+	deleteBlockEntry(c, &models.BlockedWord{}, "Blocked word")
+}
+
+// GET /api/user/blocked-senders
+func GetBlockedSenders(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var senders []models.BlockedSender
+	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&senders).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch blocked senders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Blocked senders fetched successfully", "data": senders})
+}
+
+// DELETE /api/user/blocked-senders/:id
+func DeleteBlockedSender(c *gin.Context) {
+	deleteBlockEntry(c, &models.BlockedSender{}, "Blocked sender")
+}
+
+// POST /api/messages/:id/block-sender
+func BlockMessageSender(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var message models.Message
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&message).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Message not found"})
+		return
+	}
+
+	if message.SenderFingerprint == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "message": "Sender of this message cannot be identified"})
+		return
+	}
+
+	blocked := models.BlockedSender{UserID: message.UserID, Fingerprint: message.SenderFingerprint}
+	if err := repositories.DB.Where(blocked).FirstOrCreate(&blocked).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to block sender"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Sender blocked", "data": blocked})
+}
+
+func deleteBlockEntry(c *gin.Context, entry interface{}, name string) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	result := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(entry)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete " + strings.ToLower(name)})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": name + " not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": name + " removed"})
+}
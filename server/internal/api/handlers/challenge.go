@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/challenge"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	challengeIssuer = challenge.NewIssuer(config.TokenKey("challenge"), config.Envs.ChallengeDifficulty, config.Envs.ChallengeTTL)
+	captchaVerifier = newCaptchaVerifier()
+)
+
+var errChallengeRequired = errors.New("challenge solution is required")
+
+func newCaptchaVerifier() challenge.Verifier {
+	v, err := challenge.NewVerifier(config.Envs.CaptchaProvider, config.Envs.CaptchaSecret, config.Envs.GINMode == gin.ReleaseMode)
+	if err != nil {
+		log.Printf("CAPTCHA disabled: %v\n", err)
+	}
+	return v
+}
+
+// challengeRequired reports whether the server or the recipient asks senders to solve a challenge
+func challengeRequired(user models.User) bool {
+	return config.Envs.RequireChallenge || user.RequireChallenge
+}
+
+// GET /api/messages/challenge?username=xyz
+func GetChallenge(c *gin.Context) {
+	username := c.Query("username")
+	if username == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "username query param required"})
+		return
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return
+	}
+
+	pow, err := challengeIssuer.Issue(user.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to issue challenge"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Challenge issued successfully",
+		"data": gin.H{
+			"required":        challengeRequired(user),
+			"pow":             pow,
+			"captchaProvider": config.Envs.CaptchaProvider,
+		},
+	})
+}
+
+// verifyChallenge accepts either a CAPTCHA response or an unused proof-of-work solution
+func verifyChallenge(c *gin.Context, user models.User, token, solution, captchaToken string) error {
+	if captchaToken != "" && captchaVerifier != nil {
+		ok, err := captchaVerifier.Verify(c.Request.Context(), captchaToken, c.ClientIP())
+		if err != nil {
+			log.Printf("Error verifying captcha: %v\n", err)
+		}
+		if ok {
+			return nil
+		}
+	}
+
+	if token == "" {
+		return errChallengeRequired
+	}
+
+	id, expiresAt, err := challengeIssuer.Verify(token, solution, user.Username)
+	if err != nil {
+		return err
+	}
+	return spendChallenge(id, expiresAt)
+}
+
+// challengeFailed answers a failed verification, as a server error when the
+// solution could not be checked rather than found wrong
+func challengeFailed(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, challenge.ErrExpired):
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Challenge has expired, please request a new one"})
+	case errors.Is(err, challenge.ErrInvalid), errors.Is(err, challenge.ErrInsufficientWork), errors.Is(err, errChallengeRequired):
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "A valid challenge solution is required to message this user"})
+	default:
+		log.Printf("Error verifying challenge: %v\n", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to verify challenge"})
+	}
+}
+
+// spendChallenge marks a challenge as used, failing if it already was
+func spendChallenge(id string, expiresAt time.Time) error {
+	result := repositories.DB.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.SpentChallenge{ID: id, ExpiresAt: expiresAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return challenge.ErrInvalid
+	}
+
+	// Expired challenges can never verify again, so their records can go
+	if err := repositories.DB.Where("expires_at < ?", time.Now()).Delete(&models.SpentChallenge{}).Error; err != nil {
+		log.Printf("Error pruning spent challenges: %v\n", err)
+	}
+	return nil
+}
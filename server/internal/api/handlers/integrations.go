@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/integrations"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+)
+
+const (
+	maxIntegrationsPerUser   = 10
+	maxIntegrationNameLength = 80
+)
+
+// GET /api/integrations
+func GetIntegrations(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var list []models.Integration
+	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&list).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch integrations"})
+		return
+	}
+
+	data := make([]gin.H, 0, len(list))
+	for _, i := range list {
+		data = append(data, integrationView(i))
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Integrations fetched successfully", "data": data})
+}
+
+// POST /api/integrations
+func CreateIntegration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Kind        string                   `json:"kind"`
+		Name        string                   `json:"name"`
+		Config      models.IntegrationConfig `json:"config"`
+		HideContent bool                     `json:"hideContent"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	integration := models.Integration{
+		UserID:      uid,
+		Kind:        strings.ToLower(strings.TrimSpace(input.Kind)),
+		Name:        strings.TrimSpace(input.Name),
+		Config:      trimIntegrationConfig(input.Config),
+		HideContent: input.HideContent,
+		IsActive:    true,
+	}
+	if msg := validateIntegration(integration); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	var count int64
+	repositories.DB.Model(&models.Integration{}).Where("user_id = ?", uid).Count(&count)
+	if count >= maxIntegrationsPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Integration limit reached"})
+		return
+	}
+
+	if err := repositories.DB.Create(&integration).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create integration"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Integration created successfully", "data": integrationView(integration)})
+}
+
+// PATCH /api/integrations/:id
+func UpdateIntegration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Name        *string                   `json:"name"`
+		Config      *models.IntegrationConfig `json:"config"`
+		HideContent *bool                     `json:"hideContent"`
+		IsActive    *bool                     `json:"isActive"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	var integration models.Integration
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&integration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Integration not found"})
+		return
+	}
+
+	if input.Name != nil {
+		integration.Name = strings.TrimSpace(*input.Name)
+	}
+	if input.Config != nil {
+		integration.Config = trimIntegrationConfig(*input.Config)
+	}
+	if input.HideContent != nil {
+		integration.HideContent = *input.HideContent
+	}
+	if input.IsActive != nil {
+		integration.IsActive = *input.IsActive
+		if integration.IsActive {
+			integration.LastError = ""
+		}
+	}
+	if msg := validateIntegration(integration); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	if err := repositories.DB.Save(&integration).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update integration"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Integration updated successfully", "data": integrationView(integration)})
+}
+
+// DELETE /api/integrations/:id
+func DeleteIntegration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	result := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.Integration{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete integration"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Integration not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Integration deleted successfully"})
+}
+
+// POST /api/integrations/:id/test
+// Sends a sample message right away so the owner sees the result of a bad setup
+func TestIntegration(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var integration models.Integration
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&integration).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Integration not found"})
+		return
+	}
+
+	sample := integrations.Message{
+		ID:        "test-" + uuid.NewString(),
+		Content:   "This is a test message from SilentEcho. Your integration works!",
+		CreatedAt: time.Now(),
+		URL:       config.Envs.AppURL + "/dashboard/messages",
+	}
+	client := webhook.NewClient(config.Envs.AllowPrivateWebhooks)
+	if err := worker.SendIntegration(client, integration, sample); err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"success": false, "message": "Test message failed: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Test message sent"})
+}
+
+func validateIntegration(i models.Integration) string {
+	if len(i.Name) > maxIntegrationNameLength {
+		return "Name is too long"
+	}
+	if err := integrations.Validate(i.Kind, i.Config, config.Envs.AllowPrivateWebhooks); err != nil {
+		if err == integrations.ErrUnknownKind {
+			return "Kind must be one of " + strings.Join(integrations.Kinds, ", ")
+		}
+		return "Invalid " + i.Kind + " settings: " + err.Error()
+	}
+	return ""
+}
+
+func trimIntegrationConfig(cfg models.IntegrationConfig) models.IntegrationConfig {
+	return models.IntegrationConfig{
+		WebhookURL:  strings.TrimSpace(cfg.WebhookURL),
+		Homeserver:  strings.TrimSpace(cfg.Homeserver),
+		RoomID:      strings.TrimSpace(cfg.RoomID),
+		AccessToken: strings.TrimSpace(cfg.AccessToken),
+	}
+}
+
+// integrationView adds the destination, the credentials themselves stay hidden
+func integrationView(i models.Integration) gin.H {
+	return gin.H{
+		"id":              i.ID,
+		"kind":            i.Kind,
+		"name":            i.Name,
+		"target":          integrations.Describe(i),
+		"hideContent":     i.HideContent,
+		"isActive":        i.IsActive,
+		"lastError":       i.LastError,
+		"lastDeliveredAt": i.LastDeliveredAt,
+		"createdAt":       i.CreatedAt,
+		"updatedAt":       i.UpdatedAt,
+	}
+}
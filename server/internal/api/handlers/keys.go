@@ -0,0 +1,182 @@
+package handlers
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/e2ee"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const maxMigrationBatch = 100
+
+// GET /api/user/:username/public-key
+func GetPublicKey(c *gin.Context) {
+	// gin needs one wildcard name per segment, so the username arrives as :id
+	var user models.User
+	if err := repositories.DB.Where("username = ?", c.Param("id")).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return
+	}
+
+	if user.PublicKey == "" {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User has not published a public key"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Public key fetched successfully",
+		"data": gin.H{
+			"publicKey": user.PublicKey,
+			"algorithm": e2ee.Algorithm,
+			"updatedAt": user.PublicKeyUpdatedAt,
+		},
+	})
+}
+
+// PUT /api/user/public-key
+func SetPublicKey(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		PublicKey string `json:"publicKey"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	key, err := e2ee.ParsePublicKey(input.PublicKey)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	if !updatePublicKey(c, userID, map[string]interface{}{"public_key": key, "public_key_updated_at": time.Now()}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Public key published, new messages will be end-to-end encrypted and moderation is disabled",
+	})
+}
+
+// DELETE /api/user/public-key
+func DeletePublicKey(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	if !updatePublicKey(c, userID, map[string]interface{}{"public_key": "", "public_key_updated_at": nil}) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Public key removed, existing encrypted messages stay encrypted",
+	})
+}
+
+// POST /api/messages/encrypt
+//
+// Migration path for inboxes that switch to end-to-end encryption: the
+// owner's client seals its old plaintext messages to its own key and uploads
+// them here, and the server replaces the plaintext with the ciphertext.
+func EncryptExistingMessages(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Messages []struct {
+			ID         string `json:"id"`
+			Ciphertext string `json:"ciphertext"`
+		} `json:"messages"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	if len(input.Messages) == 0 || len(input.Messages) > maxMigrationBatch {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Send between 1 and 100 messages per request"})
+		return
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return
+	}
+	if user.PublicKey == "" {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Publish a public key before encrypting messages"})
+		return
+	}
+
+	// Bad input is rejected before anything is written
+	ciphertexts := make(map[uuid.UUID]string, len(input.Messages))
+	for _, m := range input.Messages {
+		id, err := uuid.Parse(m.ID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid message ID"})
+			return
+		}
+		ciphertext, err := e2ee.ValidateSealedBox(m.Ciphertext)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid ciphertext for message " + m.ID + ": " + err.Error()})
+			return
+		}
+		ciphertexts[id] = ciphertext
+	}
+
+	var migrated int64
+	err := repositories.DB.Transaction(func(tx *gorm.DB) error {
+		for id, ciphertext := range ciphertexts {
+			result := tx.Model(&models.Message{}).
+				Where("id = ? AND user_id = ? AND is_encrypted = ?", id, user.ID, false).
+				Updates(map[string]interface{}{"content": ciphertext, "is_encrypted": true, "sim_hash": 0, "is_public": false})
+			if result.Error != nil {
+				return result.Error
+			}
+			migrated += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Error encrypting messages for user %s: %v\n", user.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to encrypt messages"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Messages encrypted", "data": gin.H{"migrated": migrated}})
+}
+
+func updatePublicKey(c *gin.Context, userID interface{}, updates map[string]interface{}) bool {
+	result := repositories.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update public key"})
+		return false
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+)
+
+// testPublicKey is any non-zero 32 byte key, the server never uses it
+var testPublicKey = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", 32)))
+
+// testSealedBox is shaped like a sealed box, which is all the server checks
+var testSealedBox = base64.StdEncoding.EncodeToString([]byte(strings.Repeat("c", 64)))
+
+func encryptRoute(owner models.User) func(r *gin.Engine) {
+	return func(r *gin.Engine) { r.POST("/messages/encrypt", asUser(owner), EncryptExistingMessages) }
+}
+
+func createTestMessage(t *testing.T, owner models.User, content string) models.Message {
+	t.Helper()
+	m := models.Message{UserID: owner.ID, Content: content, Folder: models.FolderInbox, CreatedAt: time.Now()}
+	if err := repositories.DB.Create(&m).Error; err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	return m
+}
+
+func TestEncryptExistingMessages(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t, func(u *models.User) { u.PublicKey = testPublicKey })
+	m := createTestMessage(t, owner, "plaintext")
+
+	body := gin.H{"messages": []gin.H{{"id": m.ID, "ciphertext": testSealedBox}}}
+	code, resp := serve(t, encryptRoute(owner), http.MethodPost, "/messages/encrypt", body)
+	if code != http.StatusOK {
+		t.Fatalf("encrypt = %d %s", code, resp.Message)
+	}
+
+	var got models.Message
+	repositories.DB.Where("id = ?", m.ID).First(&got)
+	if !got.IsEncrypted || got.Content != testSealedBox {
+		t.Errorf("message = %+v", got)
+	}
+}
+
+func TestEncryptExistingMessagesRejectsBadInput(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t, func(u *models.User) { u.PublicKey = testPublicKey })
+	m := createTestMessage(t, owner, "plaintext")
+
+	tests := []struct {
+		name     string
+		messages []gin.H
+	}{
+		{"bad ciphertext after a good one", []gin.H{{"id": m.ID, "ciphertext": testSealedBox}, {"id": m.ID, "ciphertext": "short"}}},
+		{"bad message ID", []gin.H{{"id": "nope", "ciphertext": testSealedBox}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, _ := serve(t, encryptRoute(owner), http.MethodPost, "/messages/encrypt", gin.H{"messages": tt.messages})
+			if code != http.StatusBadRequest {
+				t.Errorf("encrypt = %d, want 400", code)
+			}
+			var got models.Message
+			repositories.DB.Where("id = ?", m.ID).First(&got)
+			if got.IsEncrypted || got.Content != "plaintext" {
+				t.Errorf("message was changed: %+v", got)
+			}
+		})
+	}
+}
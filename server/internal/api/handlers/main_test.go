@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Handler tests need Postgres, point TEST_DB_URL at a disposable database to
+// run them. Without it they are skipped.
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	if url := os.Getenv("TEST_DB_URL"); url != "" {
+		db, err := gorm.Open(postgres.Open(url), &gorm.Config{})
+		if err != nil {
+			log.Fatalf("Failed to connect to test database: %v", err)
+		}
+		if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+			log.Fatalf("Failed to create uuid-ossp: %v", err)
+		}
+		config.Envs.DB_URL = url
+		repositories.ConnectDatabase()
+	}
+	os.Exit(m.Run())
+}
+
+func requireDB(t *testing.T) {
+	t.Helper()
+	if repositories.DB == nil {
+		t.Skip("TEST_DB_URL is not set")
+	}
+}
+
+// createTestUser stores a user with a unique name, after applying opts
+func createTestUser(t *testing.T, opts ...func(u *models.User)) models.User {
+	t.Helper()
+	name := "test" + uuid.NewString()[:8]
+	u := models.User{Username: name, Email: name + "@example.com", Password: "x", IsVerified: true, IsAcceptingMessages: true}
+	for _, opt := range opts {
+		opt(&u)
+	}
+	if err := repositories.DB.Create(&u).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	// Defaults hide zero values from Create
+	if !u.IsAcceptingMessages {
+		repositories.DB.Model(&u).Update("is_accepting_messages", false)
+	}
+	t.Cleanup(func() { repositories.DB.Delete(&u) })
+	return u
+}
+
+// asUser stands in for AuthMiddleware
+func asUser(u models.User) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("userID", u.ID.String())
+		c.Next()
+	}
+}
+
+// testResponse is the envelope every handler answers with
+type testResponse struct {
+	Success bool            `json:"success"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// serve sends one JSON request through a router built by setup
+func serve(t *testing.T, setup func(r *gin.Engine), method, path string, body any) (int, testResponse) {
+	t.Helper()
+	r := gin.New()
+	setup(r)
+
+	var buf bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&buf).Encode(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "203.0.113.7:1234"
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp testResponse
+	if w.Code != http.StatusNoContent {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid response %q", method, path, w.Body.String())
+		}
+	}
+	return w.Code, resp
+}
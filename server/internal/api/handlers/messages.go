@@ -2,21 +2,58 @@ package handlers
 
 import (
 	"errors"
+	"log"
 	"net/http"
 	"time"
 
 	"gorm.io/gorm"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/e2ee"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
 	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
 	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/spam"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
 )
 
+// messageModerator screens incoming messages before they are stored
+var messageModerator = moderation.Default()
+
+// spamScorer rates incoming messages against the recipient's recent inbox
+var spamScorer = spam.NewScorer(spam.DefaultOptions)
+
+// spamLookback bounds how far back duplicate and burst detection looks
+const spamLookback = time.Hour
+
+type MessageHandler struct {
+	events *events.Publisher
+	hub    *events.Hub
+}
+
+func NewMessageHandler(rmq *queue.RabbitMQ, hub *events.Hub) *MessageHandler {
+	return &MessageHandler{
+		events: events.NewPublisher(rmq, config.Envs.EVENTS_EXCHANGE),
+		hub:    hub,
+	}
+}
+
 // POST /api/messages
-func SendMessage(c *gin.Context) {
+func (h *MessageHandler) SendMessage(c *gin.Context) {
 	var input struct {
-		Content  string `json:"content"`
-		Username string `json:"username"`
+		Content      string `json:"content"`
+		Username     string `json:"username"`
+		Prompt       string `json:"prompt"`
+		Challenge    string `json:"challenge"`
+		Solution     string `json:"solution"`
+		CaptchaToken string `json:"captchaToken"`
+		WantsReply   bool   `json:"wantsReply"`
+		// Content is a base64 sealed box for end-to-end encrypted inboxes
+		Encrypted bool `json:"encrypted"`
 	}
 
 	if err := c.BindJSON(&input); err != nil {
@@ -24,13 +61,25 @@ func SendMessage(c *gin.Context) {
 		return
 	}
 
-	if input.Content == "" || input.Username == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Content and Username are required"})
+	if input.Content == "" || (input.Username == "" && input.Prompt == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Content and Username or Prompt are required"})
 		return
 	}
 
+	// A prompt slug identifies the recipient on its own
+	var prompt *models.Prompt
+	userQuery := repositories.DB.Where("username = ?", input.Username)
+	if input.Prompt != "" {
+		var err error
+		if prompt, err = findPromptBySlug(input.Prompt); err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Prompt not found"})
+			return
+		}
+		userQuery = repositories.DB.Where("id = ?", prompt.UserID)
+	}
+
 	var user models.User
-	if err := repositories.DB.Where("username = ?", input.Username).First(&user).Error; err != nil {
+	if err := userQuery.First(&user).Error; err != nil || (input.Username != "" && user.Username != input.Username) {
 		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
 		return
 	}
@@ -40,10 +89,100 @@ func SendMessage(c *gin.Context) {
 		return
 	}
 
+	status, err := scheduleStatus(user.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create message"})
+		return
+	}
+	if status != nil && !status.Open {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "User is not accepting messages right now", "data": gin.H{"nextOpenAt": status.NextOpenAt}})
+		return
+	}
+
+	if prompt != nil && !prompt.AcceptsMessages(time.Now()) {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "This prompt is closed"})
+		return
+	}
+
+	// Inboxes with a published key only take ciphertext, others only plaintext
+	if user.PublicKey != "" && !input.Encrypted {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "This user only accepts end-to-end encrypted messages", "data": gin.H{"publicKey": user.PublicKey, "algorithm": e2ee.Algorithm}})
+		return
+	}
+	if user.PublicKey == "" && input.Encrypted {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "This user has not published a public key"})
+		return
+	}
+	if input.Encrypted {
+		ciphertext, err := e2ee.ValidateSealedBox(input.Content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid ciphertext"})
+			return
+		}
+		input.Content = ciphertext
+	}
+
+	if challengeRequired(user) {
+		if err := verifyChallenge(c, user, input.Challenge, input.Solution, input.CaptchaToken); err != nil {
+			challengeFailed(c, err)
+			return
+		}
+	}
+
+	// Content filters cannot see inside ciphertext, so encrypted inboxes skip them
+	var plaintext string
+	if !input.Encrypted {
+		plaintext = input.Content
+	}
+
+	// Blocked submissions look successful to the sender but are never stored
+	fingerprint := senderFingerprint(c, user)
+	if isBlockedSubmission(user, fingerprint, plaintext) {
+		var decoy string
+		if input.WantsReply {
+			decoy, _ = newDecoyThreadToken()
+		}
+		messageSent(c, decoy)
+		return
+	}
+
+	verdict, score, err := screenMessage(user, input.Content, input.Encrypted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create message"})
+		return
+	}
+	if verdict.Verdict == moderation.Reject {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "message": verdict.Reason})
+		return
+	}
+
 	newMessage := models.Message{
-		Content:   input.Content,
-		UserID:    user.ID,
-		CreatedAt: time.Now(),
+		Content:           input.Content,
+		UserID:            user.ID,
+		PromptID:          promptID(prompt),
+		Folder:            models.FolderInbox,
+		SenderFingerprint: fingerprint,
+		SimHash:           int64(score.SimHash),
+		IsEncrypted:       input.Encrypted,
+		CreatedAt:         time.Now(),
+	}
+	// Quarantined messages are stored for review, the sender is not told
+	if verdict.Verdict == moderation.Quarantine {
+		newMessage.Folder = models.FolderQuarantine
+		newMessage.ModerationReason = verdict.Reason
+	}
+
+	// The thread token is only ever returned here, we keep its hash
+	var threadToken string
+	if input.WantsReply {
+		token, hash, err := utils.NewSecretToken()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create message"})
+			return
+		}
+		threadToken = token
+		newMessage.ThreadTokenHash = &hash
+		newMessage.HasThread = true
 	}
 
 	if err := repositories.DB.Create(&newMessage).Error; err != nil {
@@ -51,10 +190,86 @@ func SendMessage(c *gin.Context) {
 		return
 	}
 
+	h.publishMessageCreated(newMessage)
+	messageSent(c, threadToken)
+}
+
+// publishMessageCreated announces a stored message, failures only cost live updates
+func (h *MessageHandler) publishMessageCreated(m models.Message) {
+	event, err := events.New(events.MessageCreated, m.UserID, events.NewMessageData(m))
+	if err == nil {
+		err = h.events.Publish(event)
+	}
+	if err != nil {
+		log.Printf("Failed to publish %s for message %s: %v\n", events.MessageCreated, m.ID, err)
+	}
+}
+
+func promptID(p *models.Prompt) *uuid.UUID {
+	if p == nil {
+		return nil
+	}
+	return &p.ID
+}
+
+// messageSent writes the sender-facing success response, with the thread token if one was requested
+func messageSent(c *gin.Context, threadToken string) {
+	if threadToken != "" {
+		c.JSON(http.StatusOK, gin.H{"success": true, "message": "Message sent successfully", "data": gin.H{"threadToken": threadToken}})
+		return
+	}
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Message sent successfully"})
 }
 
-// GET /api/messages
+// screenMessage runs moderation and spam scoring for a new message.
+// Spam scoring can escalate the moderation verdict but never relax it.
+func screenMessage(user models.User, content string, encrypted bool) (moderation.Result, spam.Score, error) {
+	verdict := moderation.Result{Verdict: moderation.Allow}
+	if !encrypted {
+		level, _ := moderation.ParseSensitivity(user.ModerationLevel)
+		verdict = messageModerator.Moderate(content, moderation.Settings{
+			Sensitivity: level,
+			BlockLinks:  user.BlockLinks,
+		})
+	}
+
+	score, err := scoreSpam(user, content, encrypted)
+	if err != nil {
+		return verdict, score, err
+	}
+
+	switch {
+	case score.Total >= config.Envs.SpamRejectScore:
+		verdict = moderation.Result{Verdict: moderation.Reject, Filter: "spam", Reason: "Message was rejected as spam"}
+	case score.Total >= config.Envs.SpamQuarantineScore && verdict.Verdict < moderation.Quarantine:
+		verdict = moderation.Result{Verdict: moderation.Quarantine, Filter: "spam", Reason: score.Reason()}
+	}
+	return verdict, score, nil
+}
+
+// scoreSpam rates content against messages the recipient received recently
+func scoreSpam(user models.User, content string, encrypted bool) (spam.Score, error) {
+	var rows []models.Message
+	now := time.Now()
+	err := repositories.DB.Select("sim_hash", "is_encrypted", "created_at").
+		Where("user_id = ? AND created_at > ?", user.ID, now.Add(-spamLookback)).
+		Order("created_at desc").Limit(500).Find(&rows).Error
+	if err != nil {
+		log.Printf("Error loading recent messages for user %s: %v\n", user.ID, err)
+		return spam.Score{}, err
+	}
+
+	recent := make([]spam.Recent, len(rows))
+	for i, m := range rows {
+		recent[i] = spam.Recent{SimHash: uint64(m.SimHash), CreatedAt: m.CreatedAt, Opaque: m.IsEncrypted}
+	}
+	if encrypted {
+		return spamScorer.ScoreOpaque(recent, now), nil
+	}
+	return spamScorer.Score(content, recent, now), nil
+}
+
+// GET /api/messages?folder=inbox|quarantine&prompt=<id or slug>
 func GetMessages(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -62,8 +277,24 @@ func GetMessages(c *gin.Context) {
 		return
 	}
 
+	folder := c.DefaultQuery("folder", models.FolderInbox)
+	if folder != models.FolderInbox && folder != models.FolderQuarantine {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid folder"})
+		return
+	}
+
+	query := repositories.DB.Preload("Replies").Order("created_at desc").Where("user_id = ? AND folder = ?", userID, folder)
+	if ref := c.Query("prompt"); ref != "" {
+		prompt, err := findPromptFilter(userID, ref)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Prompt not found"})
+			return
+		}
+		query = query.Where("prompt_id = ?", prompt.ID)
+	}
+
 	var messages []models.Message
-	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&messages).Error; err != nil {
+	if err := query.Find(&messages).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch messages"})
 		return
 	}
@@ -113,3 +344,27 @@ func DeleteMessage(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Message deleted successfully"})
 }
+
+// POST /api/messages/:id/approve
+func ApproveMessage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	result := repositories.DB.Model(&models.Message{}).
+		Where("id = ? AND user_id = ? AND folder = ?", c.Param("id"), userID, models.FolderQuarantine).
+		Updates(map[string]interface{}{"folder": models.FolderInbox, "moderation_reason": ""})
+
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update message"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Quarantined message not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Message moved to inbox"})
+}
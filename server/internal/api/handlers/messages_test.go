@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"math/bits"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/challenge"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
+)
+
+func sendMessageRoute(r *gin.Engine) {
+	r.POST("/messages", NewMessageHandler(nil, nil).SendMessage)
+}
+
+func inboxOf(t *testing.T, owner models.User) []models.Message {
+	t.Helper()
+	var messages []models.Message
+	if err := repositories.DB.Where("user_id = ?", owner.ID).Order("created_at asc").Find(&messages).Error; err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+// easyChallenges swaps in an issuer tests can solve quickly
+func easyChallenges(t *testing.T) {
+	t.Helper()
+	saved := challengeIssuer
+	challengeIssuer = challenge.NewIssuer("test", 4, time.Minute)
+	t.Cleanup(func() { challengeIssuer = saved })
+}
+
+// solveChallenge brute forces a proof of work the way clients do
+func solveChallenge(t *testing.T, c challenge.Challenge) string {
+	t.Helper()
+	for n := 0; n < 1<<20; n++ {
+		solution := strconv.Itoa(n)
+		sum := sha256.Sum256([]byte(c.Token + ":" + solution))
+		zeros := 0
+		for _, b := range sum {
+			zeros += bits.LeadingZeros8(b)
+			if b != 0 {
+				break
+			}
+		}
+		if zeros >= c.Difficulty {
+			return solution
+		}
+	}
+	t.Fatal("no solution found")
+	return ""
+}
+
+func TestSendMessageStoresMessage(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+
+	code, resp := serve(t, sendMessageRoute, http.MethodPost, "/messages", gin.H{"username": owner.Username, "content": "hello there", "wantsReply": true})
+	if code != http.StatusOK {
+		t.Fatalf("send = %d %s", code, resp.Message)
+	}
+	var data struct {
+		ThreadToken string `json:"threadToken"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil || data.ThreadToken == "" {
+		t.Fatalf("no thread token in %s", resp.Data)
+	}
+
+	messages := inboxOf(t, owner)
+	if len(messages) != 1 {
+		t.Fatalf("%d messages stored", len(messages))
+	}
+	m := messages[0]
+	if m.Content != "hello there" || m.Folder != models.FolderInbox || m.SenderFingerprint == "" || !m.HasThread {
+		t.Errorf("message = %+v", m)
+	}
+	if m.ThreadTokenHash == nil || *m.ThreadTokenHash != utils.HashToken(data.ThreadToken) {
+		t.Error("thread token does not open the stored message")
+	}
+}
+
+func TestSendMessageChecks(t *testing.T) {
+	requireDB(t)
+
+	tests := []struct {
+		name  string
+		owner func(u *models.User)
+		body  gin.H
+		want  int
+	}{
+		{"closed inbox", func(u *models.User) { u.IsAcceptingMessages = false }, gin.H{"content": "hi"}, http.StatusForbidden},
+		{"no content", nil, gin.H{"content": ""}, http.StatusBadRequest},
+		{"plaintext to an encrypted inbox", func(u *models.User) { u.PublicKey = testPublicKey }, gin.H{"content": "hi"}, http.StatusBadRequest},
+		{"ciphertext to a plaintext inbox", nil, gin.H{"content": testSealedBox, "encrypted": true}, http.StatusBadRequest},
+		{"challenge required", func(u *models.User) { u.RequireChallenge = true }, gin.H{"content": "hi"}, http.StatusForbidden},
+		{"forged challenge", func(u *models.User) { u.RequireChallenge = true }, gin.H{"content": "hi", "challenge": "abc.def", "solution": "1"}, http.StatusForbidden},
+		{"rejected by moderation", func(u *models.User) { u.BlockLinks = true; u.ModerationLevel = "high" }, gin.H{"content": "see https://example.com"}, http.StatusUnprocessableEntity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []func(u *models.User)
+			if tt.owner != nil {
+				opts = append(opts, tt.owner)
+			}
+			owner := createTestUser(t, opts...)
+			tt.body["username"] = owner.Username
+
+			code, resp := serve(t, sendMessageRoute, http.MethodPost, "/messages", tt.body)
+			if code != tt.want {
+				t.Errorf("send = %d %s, want %d", code, resp.Message, tt.want)
+			}
+			if n := len(inboxOf(t, owner)); n != 0 {
+				t.Errorf("%d messages stored", n)
+			}
+		})
+	}
+
+	code, _ := serve(t, sendMessageRoute, http.MethodPost, "/messages", gin.H{"username": "no-such-user-here", "content": "hi"})
+	if code != http.StatusNotFound {
+		t.Errorf("unknown recipient = %d, want 404", code)
+	}
+}
+
+func TestSendMessageQuarantines(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t, func(u *models.User) { u.BlockLinks = true })
+
+	code, resp := serve(t, sendMessageRoute, http.MethodPost, "/messages", gin.H{"username": owner.Username, "content": "see https://example.com"})
+	if code != http.StatusOK {
+		t.Fatalf("send = %d %s, quarantine must look like success", code, resp.Message)
+	}
+	messages := inboxOf(t, owner)
+	if len(messages) != 1 || messages[0].Folder != models.FolderQuarantine || messages[0].ModerationReason == "" {
+		t.Errorf("messages = %+v", messages)
+	}
+}
+
+func TestSendMessageScoresRepeats(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+
+	var rejected int
+	for i := 0; i < 15; i++ {
+		code, _ := serve(t, sendMessageRoute, http.MethodPost, "/messages", gin.H{"username": owner.Username, "content": "buy followers now"})
+		if code == http.StatusUnprocessableEntity {
+			rejected++
+		}
+	}
+
+	var screened int
+	for _, m := range inboxOf(t, owner) {
+		if m.Folder == models.FolderQuarantine {
+			screened++
+		}
+	}
+	if rejected+screened == 0 {
+		t.Error("a flood of identical messages all reached the inbox")
+	}
+}
+
+func TestSendMessageSpendsChallenges(t *testing.T) {
+	requireDB(t)
+	easyChallenges(t)
+	owner := createTestUser(t, func(u *models.User) { u.RequireChallenge = true })
+
+	pow, err := challengeIssuer.Issue(owner.Username)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := gin.H{"username": owner.Username, "content": "hi", "challenge": pow.Token, "solution": solveChallenge(t, pow)}
+
+	if code, resp := serve(t, sendMessageRoute, http.MethodPost, "/messages", body); code != http.StatusOK {
+		t.Fatalf("send = %d %s", code, resp.Message)
+	}
+	if code, _ := serve(t, sendMessageRoute, http.MethodPost, "/messages", body); code != http.StatusForbidden {
+		t.Errorf("replayed solution = %d, want 403", code)
+	}
+	if n := len(inboxOf(t, owner)); n != 1 {
+		t.Errorf("%d messages stored, want 1", n)
+	}
+}
+
+func TestSendMessageDecoysBlockedSenders(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	blocked := models.BlockedSender{
+		UserID:      owner.ID,
+		Fingerprint: utils.SenderFingerprint(config.Envs.FingerprintSalt, owner.ID.String(), "203.0.113.7", ""),
+	}
+	if err := repositories.DB.Create(&blocked).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	code, resp := serve(t, sendMessageRoute, http.MethodPost, "/messages", gin.H{"username": owner.Username, "content": "hi", "wantsReply": true})
+	if code != http.StatusOK {
+		t.Fatalf("send = %d %s, want the usual success", code, resp.Message)
+	}
+	var data struct {
+		ThreadToken string `json:"threadToken"`
+	}
+	json.Unmarshal(resp.Data, &data)
+	if _, ok := decoyThread(data.ThreadToken); !ok {
+		t.Errorf("thread token %q is not a decoy", data.ThreadToken)
+	}
+	if n := len(inboxOf(t, owner)); n != 0 {
+		t.Errorf("%d messages stored from a blocked sender", n)
+	}
+}
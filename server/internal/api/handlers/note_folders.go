@@ -0,0 +1,190 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	maxNoteFoldersPerUser = 100
+	maxNoteFolderName     = 64
+)
+
+// GET /api/notes/folders
+func GetNoteFolders(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var folders []struct {
+		models.NoteFolder
+		NoteCount int64 `json:"noteCount"`
+	}
+	err := repositories.DB.Model(&models.NoteFolder{}).
+		Select("note_folders.*, (SELECT COUNT(*) FROM notes WHERE notes.folder_id = note_folders.id) AS note_count").
+		Where("user_id = ?", userID).
+		Order("name").
+		Scan(&folders).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch folders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Folders fetched successfully", "data": folders})
+}
+
+// POST /api/notes/folders
+func CreateNoteFolder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	folder := models.NoteFolder{UserID: uid, Name: strings.TrimSpace(input.Name)}
+	if msg := validateNoteFolder(folder); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	var count int64
+	repositories.DB.Model(&models.NoteFolder{}).Where("user_id = ?", uid).Count(&count)
+	if count >= maxNoteFoldersPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Folder limit reached"})
+		return
+	}
+
+	if err := repositories.DB.Create(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "A folder with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create folder"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Folder created successfully", "data": folder})
+}
+
+// PATCH /api/notes/folders/:id
+func UpdateNoteFolder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Name string `json:"name"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	var folder models.NoteFolder
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&folder).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Folder not found"})
+		return
+	}
+
+	folder.Name = strings.TrimSpace(input.Name)
+	if msg := validateNoteFolder(folder); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	if err := repositories.DB.Save(&folder).Error; err != nil {
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "A folder with this name already exists"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update folder"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Folder updated successfully", "data": folder})
+}
+
+// DELETE /api/notes/folders/:id
+// The folder's notes are kept and become unfiled
+func DeleteNoteFolder(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	result := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.NoteFolder{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete folder"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Folder not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Folder deleted successfully"})
+}
+
+// GET /api/notes/labels
+// Lists the labels in use across the owner's notes
+func GetNoteLabels(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var labels []struct {
+		Label     string `json:"label"`
+		NoteCount int64  `json:"noteCount"`
+	}
+	err := repositories.DB.Model(&models.NoteLabel{}).
+		Select("note_labels.label, COUNT(*) AS note_count").
+		Joins("JOIN notes ON notes.id = note_labels.note_id").
+		Where("notes.user_id = ?", userID).
+		Group("note_labels.label").
+		Order("note_labels.label").
+		Scan(&labels).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch labels"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Labels fetched successfully", "data": labels})
+}
+
+func validateNoteFolder(f models.NoteFolder) string {
+	if f.Name == "" || utf8.RuneCountInString(f.Name) > maxNoteFolderName {
+		return fmt.Sprintf("Folder name must be between 1 and %d characters", maxNoteFolderName)
+	}
+	return ""
+}
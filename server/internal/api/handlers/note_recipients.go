@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/e2ee"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxNoteRecipients       = 20
+	maxSharedNotesPageSize  = 100
+	defaultSharedNotesLimit = 20
+)
+
+// recipientInput is the note key sealed to one user's published public key.
+// PublicKey echoes the key the client sealed to, so a key that changed in
+// the meantime is caught instead of sharing a note nobody can open.
+type recipientInput struct {
+	Username   string `json:"username"`
+	PublicKey  string `json:"publicKey"`
+	WrappedKey string `json:"wrappedKey"`
+}
+
+// resolveRecipients looks up the users a note is shared with and checks their
+// wrapped keys, returning a status and message when they are unusable
+func resolveRecipients(inputs []recipientInput) ([]models.NoteRecipient, int, string) {
+	if len(inputs) > maxNoteRecipients {
+		return nil, http.StatusBadRequest, fmt.Sprintf("Notes can be shared with at most %d users", maxNoteRecipients)
+	}
+
+	seen := make(map[uuid.UUID]bool, len(inputs))
+	recipients := make([]models.NoteRecipient, 0, len(inputs))
+	for _, in := range inputs {
+		var user models.User
+		if err := repositories.DB.Select("id", "public_key").Where("username = ?", in.Username).First(&user).Error; err != nil {
+			return nil, http.StatusNotFound, fmt.Sprintf("User %s not found", in.Username)
+		}
+		if user.PublicKey == "" {
+			return nil, http.StatusBadRequest, fmt.Sprintf("User %s has not published a public key", in.Username)
+		}
+		key, err := e2ee.ParsePublicKey(in.PublicKey)
+		if err != nil || key != user.PublicKey {
+			return nil, http.StatusConflict, fmt.Sprintf("Public key of %s has changed, seal the note key to the current one", in.Username)
+		}
+		wrapped, err := e2ee.ValidateSealedBox(in.WrappedKey)
+		if err != nil {
+			return nil, http.StatusBadRequest, fmt.Sprintf("Wrapped key for %s is invalid: %v", in.Username, err)
+		}
+		if seen[user.ID] {
+			continue
+		}
+		seen[user.ID] = true
+		recipients = append(recipients, models.NoteRecipient{UserID: user.ID, PublicKey: key, WrappedKey: wrapped})
+	}
+	return recipients, 0, ""
+}
+
+// saveRecipients shares the note with recipients, re-sharing with anyone who
+// was revoked before under the new wrapped key
+func saveRecipients(tx *gorm.DB, noteID uuid.UUID, recipients []models.NoteRecipient) error {
+	if len(recipients) == 0 {
+		return nil
+	}
+	for i := range recipients {
+		recipients[i].NoteID = noteID
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "note_id"}, {Name: "user_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"public_key":  gorm.Expr("excluded.public_key"),
+			"wrapped_key": gorm.Expr("excluded.wrapped_key"),
+			"revoked_at":  nil,
+		}),
+	}).Create(&recipients).Error
+}
+
+// GET /api/notes/:slug/recipients
+func GetNoteRecipients(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	note, ok := findOwnedNote(c, userID)
+	if !ok {
+		return
+	}
+
+	var recipients []models.NoteRecipient
+	if err := repositories.DB.Preload("User").Where("note_id = ?", note.ID).Order("created_at").Find(&recipients).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch recipients"})
+		return
+	}
+
+	data := make([]gin.H, 0, len(recipients))
+	for _, r := range recipients {
+		data = append(data, noteRecipientView(r))
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Recipients fetched successfully", "data": data})
+}
+
+// POST /api/notes/:slug/recipients
+func AddNoteRecipients(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Recipients []recipientInput `json:"recipients"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || len(input.Recipients) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Recipients are required"})
+		return
+	}
+
+	note, ok := findOwnedNote(c, userID)
+	if !ok {
+		return
+	}
+	if !note.Live(time.Now()) {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Only notes that can still be read can be shared"})
+		return
+	}
+
+	recipients, status, msg := resolveRecipients(input.Recipients)
+	if msg != "" {
+		c.JSON(status, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	err := repositories.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveRecipients(tx, note.ID, recipients); err != nil {
+			return err
+		}
+		var count int64
+		tx.Model(&models.NoteRecipient{}).Where("note_id = ? AND revoked_at IS NULL", note.ID).Count(&count)
+		if count > maxNoteRecipients {
+			return errTooManyRecipients
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errTooManyRecipients) {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": fmt.Sprintf("Notes can be shared with at most %d users", maxNoteRecipients)})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to share note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note shared successfully"})
+}
+
+var errTooManyRecipients = errors.New("too many recipients")
+
+// DELETE /api/notes/:slug/recipients/:username
+// Revoking drops the recipient's wrapped key. Anyone who already opened the
+// note has seen the note key, so sensitive notes should be re-encrypted.
+func RevokeNoteRecipient(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	note, ok := findOwnedNote(c, userID)
+	if !ok {
+		return
+	}
+
+	result := repositories.DB.Model(&models.NoteRecipient{}).
+		Where("note_id = ? AND revoked_at IS NULL AND user_id = (SELECT id FROM users WHERE username = ?)", note.ID, c.Param("username")).
+		Updates(map[string]interface{}{"revoked_at": time.Now(), "wrapped_key": ""})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to revoke recipient"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Recipient not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Recipient revoked successfully"})
+}
+
+// GET /api/notes/shared?page=1&limit=20
+// Lists the notes other users shared with the caller
+func GetSharedNotes(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(defaultSharedNotesLimit)))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxSharedNotesPageSize {
+		limit = maxSharedNotesPageSize
+	}
+
+	// Drafts stay hidden from recipients until the owner publishes them
+	query := repositories.DB.Model(&models.NoteRecipient{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Where("note_id IN (SELECT id FROM notes WHERE NOT draft)")
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch shared notes"})
+		return
+	}
+
+	var shares []models.NoteRecipient
+	err := query.Preload("Note", func(db *gorm.DB) *gorm.DB { return db.Omit("cipher_note") }).
+		Preload("Note.User").
+		Order("created_at desc").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&shares).Error
+	if err != nil {
+		log.Printf("Error fetching notes shared with %v: %v\n", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch shared notes"})
+		return
+	}
+
+	now := time.Now()
+	items := make([]gin.H, 0, len(shares))
+	for _, s := range shares {
+		items = append(items, gin.H{
+			"slug":           s.Note.Slug,
+			"owner":          s.Note.User.Username,
+			"status":         s.Note.Status(now),
+			"sharedAt":       s.CreatedAt,
+			"lastViewedAt":   s.LastViewedAt,
+			"createdAt":      s.Note.CreatedAt,
+			"expiresAt":      s.Note.ExpiresAt,
+			"viewsRemaining": viewsRemaining(s.Note),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Shared notes fetched successfully",
+		"data": gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"items": items,
+		},
+	})
+}
+
+// GET /api/notes/shared/:slug
+// Reads a note shared with the caller, along with the note key sealed to them.
+// Recipients prove themselves by signing in, so access keys do not apply.
+func (h *NoteHandler) GetSharedNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var share models.NoteRecipient
+	err := repositories.DB.Preload("Note").Preload("Note.User").
+		Joins("JOIN notes ON notes.id = note_recipients.note_id").
+		Where("notes.slug = ? AND note_recipients.user_id = ? AND note_recipients.revoked_at IS NULL", c.Param("slug"), userID).
+		First(&share).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch note"})
+		}
+		return
+	}
+
+	if status := share.Note.Status(time.Now()); status != models.NoteActive {
+		refuseNote(c, share.Note, status)
+		return
+	}
+
+	released := h.releaseNote(c, share.Note, gin.H{
+		"owner":      share.Note.User.Username,
+		"publicKey":  share.PublicKey,
+		"wrappedKey": share.WrappedKey,
+	})
+	if released {
+		repositories.DB.Model(&share).UpdateColumn("last_viewed_at", time.Now())
+	}
+}
+
+func noteRecipientView(r models.NoteRecipient) gin.H {
+	return gin.H{
+		"username":     r.User.Username,
+		"publicKey":    r.PublicKey,
+		"sharedAt":     r.CreatedAt,
+		"revokedAt":    r.RevokedAt,
+		"lastViewedAt": r.LastViewedAt,
+	}
+}
@@ -2,23 +2,52 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
 	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/noteenvelope"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
 	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/slugs"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // CreateNote - POST /note
 func CreateNote(c *gin.Context) {
+	authUserID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
 	var input struct {
-		CipherNote string     `json:"ciphertext"`
-		UserID     string     `json:"userId"`
-		ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+		Envelope     *noteenvelope.Envelope `json:"envelope"`
+		CipherNote   string                 `json:"ciphertext"`
+		ExpiresAt    *time.Time             `json:"expiresAt,omitempty"`
+		MaxViews     *int                   `json:"maxViews,omitempty"`
+		NotifyOnView bool                   `json:"notifyOnView"`
+		AccessKey    string                 `json:"accessKey"`
+		MaxAttempts  *int                   `json:"maxAttempts,omitempty"`
+		Slug         string                 `json:"slug"`
+		SlugStyle    string                 `json:"slugStyle"`
+		Title        string                 `json:"title"`
+		FolderID     *uuid.UUID             `json:"folderId,omitempty"`
+		Labels       []string               `json:"labels"`
+		Recipients   []recipientInput       `json:"recipients"`
+		Draft        bool                   `json:"draft"`
+		NotBefore    *time.Time             `json:"notBefore,omitempty"`
 	}
 
 	if err := c.BindJSON(&input); err != nil {
@@ -26,31 +55,316 @@ func CreateNote(c *gin.Context) {
 		return
 	}
 
-	// Convert userId string -> UUID
-	userID, err := uuid.Parse(input.UserID)
+	// Notes always belong to the signed in user
+	userID, err := parseUserID(authUserID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid userId format"})
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	if input.MaxViews != nil && *input.MaxViews < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Max views must be at least 1"})
+		return
+	}
+
+	envelope, msg := parseNoteEnvelope(input.Envelope, input.CipherNote)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	gen, msg := noteSlugGenerator(input.SlugStyle)
+	if msg == "" && input.Slug != "" {
+		msg = checkCustomSlug(input.Slug)
+	}
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	labels, msg := noteLabels(input.Labels)
+	if msg == "" {
+		msg = checkNoteMetadata(userID, input.Title, input.FolderID)
+	}
+	if msg == "" {
+		msg = checkNoteSchedule(input.NotBefore, input.ExpiresAt)
+	}
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	recipients, status, msg := resolveRecipients(input.Recipients)
+	if msg != "" {
+		c.JSON(status, gin.H{"success": false, "message": msg})
 		return
 	}
 
 	note := models.Note{
-		Slug:       uuid.NewString(),
-		CipherNote: input.CipherNote,
-		UserID:     userID,
-		CreatedAt:  time.Now(),
-		ExpiresAt:  input.ExpiresAt,
+		Slug:           input.Slug,
+		UserID:         &userID,
+		CreatedAt:      time.Now(),
+		ExpiresAt:      input.ExpiresAt,
+		MaxViews:       input.MaxViews,
+		NotifyOnView:   input.NotifyOnView,
+		Title:          input.Title,
+		FolderID:       input.FolderID,
+		Labels:         labels,
+		RecipientsOnly: len(recipients) > 0,
+		Draft:          input.Draft,
+		NotBefore:      input.NotBefore,
+	}
+	setNoteEnvelope(&note, envelope)
+	if msg := setAccessKey(&note, input.AccessKey, input.MaxAttempts); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	if err := insertNote(&note, gen); err != nil {
+		if errors.Is(err, errSlugTaken) {
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Slug is already taken"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create note"})
+		return
+	}
+
+	if err := saveRecipients(repositories.DB, note.ID, recipients); err != nil {
+		log.Printf("Error sharing note %s: %v\n", note.ID, err)
+		repositories.DB.Delete(&note)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note created successfully", "data": gin.H{"id": note.ID, "slug": note.Slug}})
+}
+
+// slugAttempts bounds how often a generated slug that is already taken is
+// replaced before giving up
+const slugAttempts = 5
+
+var errSlugTaken = errors.New("slug is already taken")
+
+// noteSlugGenerator picks the generator for a requested style, the configured
+// one when empty, returning an error message when the style is unknown
+func noteSlugGenerator(style string) (slugs.Generator, string) {
+	if style == "" {
+		style = config.Envs.NoteSlugStyle
+	}
+	if !slugs.ValidStyle(style) {
+		return slugs.Generator{}, "Slug style must be one of base62, words or uuid"
+	}
+	return slugs.Generator{
+		Style:  style,
+		Length: config.Envs.NoteSlugLength,
+		Words:  config.Envs.NoteSlugWords,
+	}, ""
+}
+
+func checkCustomSlug(slug string) string {
+	if err := slugs.CheckCustom(slug); err != nil {
+		return "Invalid slug: " + err.Error()
+	}
+	return ""
+}
+
+// insertNote creates the note under its custom slug, or under a generated one
+// which is drawn again when the unique index says it is taken
+func insertNote(n *models.Note, gen slugs.Generator) error {
+	if n.Slug != "" {
+		err := repositories.DB.Create(n).Error
+		if errors.Is(err, gorm.ErrDuplicatedKey) {
+			return errSlugTaken
+		}
+		return err
+	}
+
+	var err error
+	for range slugAttempts {
+		if n.Slug, err = gen.New(); err != nil {
+			return err
+		}
+		// The insert runs in its own transaction so a collision can be retried
+		err = repositories.DB.Create(n).Error
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return err
+		}
+	}
+	log.Printf("Gave up on a free %s note slug after %d attempts\n", gen.Style, slugAttempts)
+	return err
+}
+
+// managementTokenHeader carries the token of an anonymous note, kept out of
+// URLs so it does not end up in logs
+const managementTokenHeader = "X-Management-Token"
+
+const maxNoteViewsPageSize = 100
+
+// Limits of the owner's note metadata
+const (
+	maxNoteTitleSize   = 1024
+	maxNoteLabels      = 10
+	maxNoteLabelLength = 32
+	maxNotesPageSize   = 100
+)
+
+// defaultAccessAttempts is the attempt limit of gated notes that set none
+const defaultAccessAttempts = 5
+
+func refuseRecipientsOnly(c *gin.Context) {
+	c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Note is shared with specific users, sign in to read it", "data": gin.H{"recipientsOnly": true}})
+}
+
+// noteLockedStatus is reported for active notes still waiting for their
+// access key
+const noteLockedStatus = "locked"
+
+type NoteHandler struct {
+	events *events.Publisher
+}
+
+func NewNoteHandler(rmq *queue.RabbitMQ) *NoteHandler {
+	return &NoteHandler{events: events.NewPublisher(rmq, config.Envs.EVENTS_EXCHANGE)}
+}
+
+// POST /api/notes/anonymous
+// Creates a note without an account, the response carries the only copy of
+// the management token
+func CreateAnonymousNote(c *gin.Context) {
+	if !config.Envs.AllowAnonymousNotes {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Anonymous notes are disabled on this server"})
+		return
+	}
+
+	var input struct {
+		Envelope    *noteenvelope.Envelope `json:"envelope"`
+		CipherNote  string                 `json:"ciphertext"`
+		ExpiresAt   *time.Time             `json:"expiresAt,omitempty"`
+		MaxViews    *int                   `json:"maxViews,omitempty"`
+		AccessKey   string                 `json:"accessKey"`
+		MaxAttempts *int                   `json:"maxAttempts,omitempty"`
+		SlugStyle   string                 `json:"slugStyle"`
+		NotBefore   *time.Time             `json:"notBefore,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	envelope, msg := parseNoteEnvelope(input.Envelope, input.CipherNote)
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	if input.MaxViews != nil && *input.MaxViews < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Max views must be at least 1"})
+		return
+	}
+
+	// Nobody owns these notes, so they never outlive the configured maximum
+	now := time.Now()
+	latest := now.Add(config.Envs.AnonymousNoteMaxTTL)
+	expiresAt := input.ExpiresAt
+	if expiresAt == nil {
+		expiresAt = &latest
+	}
+	if !expiresAt.After(now) || expiresAt.After(latest) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Expiry must be in the future and within " + config.Envs.AnonymousNoteMaxTTL.String()})
+		return
+	}
+
+	gen, msg := noteSlugGenerator(input.SlugStyle)
+	if msg == "" {
+		msg = checkNoteSchedule(input.NotBefore, expiresAt)
+	}
+	if msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	token, hash, err := utils.NewSecretToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create note"})
+		return
+	}
+
+	note := models.Note{
+		CreatedAt:           now,
+		ExpiresAt:           expiresAt,
+		MaxViews:            input.MaxViews,
+		NotBefore:           input.NotBefore,
+		ManagementTokenHash: &hash,
+	}
+	setNoteEnvelope(&note, envelope)
+	if msg := setAccessKey(&note, input.AccessKey, input.MaxAttempts); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
 	}
 
-	if err := repositories.DB.Create(&note).Error; err != nil {
+	if err := insertNote(&note, gen); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create note"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note created successfully", "data": gin.H{"id": note.ID}})
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Note created successfully", "data": gin.H{
+		"id":              note.ID,
+		"slug":            note.Slug,
+		"expiresAt":       note.ExpiresAt,
+		"managementToken": token,
+	}})
+}
+
+// GET /api/notes/:slug/manage
+// Lets the creator of an anonymous note check on it without using up a view
+func InspectAnonymousNote(c *gin.Context) {
+	note, ok := findManagedNote(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note fetched successfully", "data": noteSummary(note, time.Now())})
+}
+
+// DELETE /api/notes/:slug/manage
+func DeleteAnonymousNote(c *gin.Context) {
+	note, ok := findManagedNote(c)
+	if !ok {
+		return
+	}
+
+	if err := repositories.DB.Delete(&note).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete note"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note deleted successfully"})
+}
+
+// findManagedNote loads the :slug note matching the X-Management-Token header
+func findManagedNote(c *gin.Context) (models.Note, bool) {
+	var note models.Note
+
+	token := c.GetHeader(managementTokenHeader)
+	if token == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Management token is required"})
+		return note, false
+	}
+
+	err := repositories.DB.
+		Where("slug = ? AND management_token_hash = ?", c.Param("slug"), utils.HashToken(token)).
+		First(&note).Error
+	if err != nil {
+		// Wrong token and unknown slug look the same
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		return note, false
+	}
+	return note, true
 }
 
 // GET /note/:slug
-func GetNote(c *gin.Context) {
+func (h *NoteHandler) GetNote(c *gin.Context) {
 	slugParam := c.Param("slug")
 
 	if slugParam == "" {
@@ -70,30 +384,894 @@ func GetNote(c *gin.Context) {
 		return
 	}
 
+	if status := note.Status(time.Now()); status != models.NoteActive {
+		refuseNote(c, note, status)
+		return
+	}
+
+	if note.RecipientsOnly {
+		refuseRecipientsOnly(c)
+		return
+	}
+
+	if note.Gated() {
+		noteLocked(c, note)
+		return
+	}
+
+	h.releaseNote(c, note, nil)
+}
+
+// POST /api/notes/:slug/unlock
+// Releases a gated note to a matching access key. Each wrong key counts
+// towards the note's attempt limit, after which it is destroyed for good.
+func (h *NoteHandler) UnlockNote(c *gin.Context) {
+	var input struct {
+		AccessKey string `json:"accessKey" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Access key is required"})
+		return
+	}
+
+	var note models.Note
+	if err := repositories.DB.Where("slug = ?", c.Param("slug")).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch note"})
+		}
+		return
+	}
+
+	if status := note.Status(time.Now()); status != models.NoteActive {
+		refuseNote(c, note, status)
+		return
+	}
+
+	// Recipients read through /shared/:slug, an access key does not open the note to anyone else
+	if note.RecipientsOnly {
+		refuseRecipientsOnly(c)
+		return
+	}
+
+	if !note.Gated() {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Note is not protected by an access key"})
+		return
+	}
+
+	if noteenvelope.CheckAccessKey(*note.AccessKeyHash, input.AccessKey) {
+		// Only consecutive failures count towards the limit
+		if note.FailedAttempts > 0 {
+			repositories.DB.Model(&models.Note{}).
+				Where("id = ? AND destroyed_at IS NULL", note.ID).
+				UpdateColumn("failed_attempts", 0)
+		}
+		h.releaseNote(c, note, nil)
+		return
+	}
+
+	// Concurrent guesses are each counted, the one reaching the limit burns
+	// the note
+	err := repositories.DB.Model(&models.Note{}).
+		Where("id = ? AND destroyed_at IS NULL", note.ID).
+		UpdateColumn("failed_attempts", gorm.Expr("failed_attempts + 1")).Error
+	if err != nil {
+		log.Printf("Error counting failed unlock of note %s: %v\n", note.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to unlock note"})
+		return
+	}
+
+	updates := note.Wipe()
+	updates["destroyed_at"] = time.Now()
+	result := repositories.DB.Model(&models.Note{}).
+		Where("id = ? AND destroyed_at IS NULL AND failed_attempts >= max_attempts", note.ID).
+		Updates(updates)
+	if result.Error != nil {
+		log.Printf("Error destroying note %s: %v\n", note.ID, result.Error)
+	}
+	if result.RowsAffected > 0 {
+		h.publishNoteEvent(events.NoteDestroyed, note)
+		noteUnavailable(c, models.NoteDestroyed)
+		return
+	}
+
+	repositories.DB.Model(&models.Note{}).Where("id = ?", note.ID).Pluck("failed_attempts", &note.FailedAttempts)
+	c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "Incorrect access key", "data": gin.H{
+		"attemptsRemaining": attemptsRemaining(note),
+	}})
+}
+
+// releaseNote counts a read of an active note and answers with its
+// ciphertext and any extra fields for the reader, reporting whether it did
+func (h *NoteHandler) releaseNote(c *gin.Context, note models.Note, extra gin.H) bool {
+	// Count the read, losing the race for the last view counts as exhausted
+	result := repositories.DB.Model(&models.Note{}).
+		Where("id = ? AND revoked_at IS NULL AND destroyed_at IS NULL AND (max_views IS NULL OR view_count < max_views)", note.ID).
+		UpdateColumn("view_count", gorm.Expr("view_count + 1"))
+	if result.Error != nil {
+		log.Printf("Error counting view of note %s: %v\n", note.ID, result.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch note"})
+		return false
+	}
+	if result.RowsAffected == 0 {
+		noteUnavailable(c, models.NoteExhausted)
+		return false
+	}
+	note.ViewCount++
+
+	first := recordNoteView(c, &note)
+	h.publishNoteEvent(events.NoteViewed, note)
+	if first {
+		h.publishNoteEvent(events.NoteFirstViewed, note)
+	}
+
+	// Clients that predate envelopes only understand ciphernote
+	envelope, ok := noteEnvelope(note)
+	legacy := note.CipherNote
+	if ok {
+		legacy, _ = noteenvelope.Legacy(envelope)
+	}
+
+	data := gin.H{
+		"status":         models.NoteActive,
+		"envelope":       envelopeOrNil(envelope, ok),
+		"ciphernote":     legacy,
+		"createdAt":      note.CreatedAt,
+		"expiresAt":      note.ExpiresAt,
+		"viewsRemaining": viewsRemaining(note),
+		"attachments":    noteAttachments(note),
+	}
+	for k, v := range extra {
+		data[k] = v
+	}
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note fetched successfully", "data": data})
+	return true
+}
+
+// parseNoteEnvelope validates a new envelope, or a ciphertext in the legacy
+// format from older clients, returning an error message when it is invalid
+func parseNoteEnvelope(envelope *noteenvelope.Envelope, legacy string) (noteenvelope.Envelope, string) {
+	var (
+		e   noteenvelope.Envelope
+		err error
+	)
+	switch {
+	case envelope != nil:
+		e, err = noteenvelope.Validate(*envelope, config.Envs.NoteMaxCiphertextSize)
+	case legacy != "":
+		e, err = noteenvelope.ParseLegacy(legacy, config.Envs.NoteMaxCiphertextSize)
+	default:
+		return e, "Ciphertext envelope is required"
+	}
+	if err != nil {
+		return e, "Invalid ciphertext envelope: " + err.Error()
+	}
+	return e, ""
+}
+
+// setNoteEnvelope stores an envelope in the note's structured columns
+func setNoteEnvelope(n *models.Note, e noteenvelope.Envelope) {
+	n.EnvelopeVersion = e.Version
+	n.KDF = e.KDF
+	n.KDFIterations = e.Iterations
+	n.KDFMemory = e.Memory
+	n.KDFParallelism = e.Parallelism
+	n.Salt = e.Salt
+	n.IV = e.IV
+	n.CipherNote = e.Ciphertext
+}
+
+// noteEnvelopeColumns are the columns setNoteEnvelope fills, for updates
+// that only write what changed
+func noteEnvelopeColumns(n models.Note) map[string]interface{} {
+	return map[string]interface{}{
+		"envelope_version": n.EnvelopeVersion,
+		"kdf":              n.KDF,
+		"kdf_iterations":   n.KDFIterations,
+		"kdf_memory":       n.KDFMemory,
+		"kdf_parallelism":  n.KDFParallelism,
+		"salt":             n.Salt,
+		"iv":               n.IV,
+		"cipher_note":      n.CipherNote,
+	}
+}
+
+// noteEnvelope reads the envelope back, parsing rows from before envelopes
+func noteEnvelope(n models.Note) (noteenvelope.Envelope, bool) {
+	if n.EnvelopeVersion == 0 {
+		e, err := noteenvelope.ParseLegacy(n.CipherNote, len(n.CipherNote))
+		return e, err == nil
+	}
+	return noteenvelope.Envelope{
+		Version:     n.EnvelopeVersion,
+		KDF:         n.KDF,
+		Iterations:  n.KDFIterations,
+		Memory:      n.KDFMemory,
+		Parallelism: n.KDFParallelism,
+		Salt:        n.Salt,
+		IV:          n.IV,
+		Ciphertext:  n.CipherNote,
+	}, true
+}
+
+func envelopeOrNil(e noteenvelope.Envelope, ok bool) *noteenvelope.Envelope {
+	if !ok {
+		return nil
+	}
+	return &e
+}
+
+// noteLocked describes a gated note without its ciphertext, readers get the
+// KDF parameters they need to derive the access key
+func noteLocked(c *gin.Context, note models.Note) {
+	envelope, ok := noteEnvelope(note)
+	envelope.Ciphertext = ""
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Note is protected by an access key",
+		"data": gin.H{
+			"status":            noteLockedStatus,
+			"requiresAccessKey": true,
+			"envelope":          envelopeOrNil(envelope, ok),
+			"createdAt":         note.CreatedAt,
+			"expiresAt":         note.ExpiresAt,
+			"viewsRemaining":    viewsRemaining(note),
+			"attemptsRemaining": attemptsRemaining(note),
+		},
+	})
+}
+
+// setAccessKey gates the note behind key, if given, and sets its attempt
+// limit. It returns an error message when either is invalid.
+func setAccessKey(n *models.Note, key string, maxAttempts *int) string {
+	if maxAttempts != nil {
+		if *maxAttempts < 1 || *maxAttempts > config.Envs.NoteMaxAccessAttempts {
+			return "Max attempts must be between 1 and " + strconv.Itoa(config.Envs.NoteMaxAccessAttempts)
+		}
+		n.MaxAttempts = *maxAttempts
+	}
+	if key != "" {
+		hash, err := noteenvelope.HashAccessKey(key)
+		if err != nil {
+			return "Invalid access key: " + err.Error()
+		}
+		n.AccessKeyHash = &hash
+		n.FailedAttempts = 0
+	}
+	if !n.Gated() {
+		if maxAttempts != nil {
+			return "Max attempts needs an access key"
+		}
+		return ""
+	}
+	if n.MaxAttempts == 0 {
+		n.MaxAttempts = min(defaultAccessAttempts, config.Envs.NoteMaxAccessAttempts)
+	}
+	return ""
+}
+
+func attemptsRemaining(n models.Note) *int {
+	if !n.Gated() {
+		return nil
+	}
+	left := max(n.MaxAttempts-n.FailedAttempts, 0)
+	return &left
+}
+
+// refuseNote answers for a note that cannot be read in its current status.
+// Drafts do not exist as far as readers know, scheduled notes tell readers
+// when to come back.
+func refuseNote(c *gin.Context, n models.Note, status string) {
+	switch status {
+	case models.NoteDraft:
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+	case models.NoteScheduled:
+		c.JSON(http.StatusTooEarly, gin.H{"success": false, "message": "Note cannot be read yet", "data": gin.H{
+			"status":    status,
+			"notBefore": n.NotBefore,
+		}})
+	default:
+		noteUnavailable(c, status)
+	}
+}
+
+// checkNoteSchedule returns an error message when a note would expire before
+// it is released
+func checkNoteSchedule(notBefore, expiresAt *time.Time) string {
+	if notBefore != nil && expiresAt != nil && !notBefore.Before(*expiresAt) {
+		return "Release time must be before the expiry"
+	}
+	return ""
+}
+
+// noteUnavailable answers with 410 and the reason, so readers can tell a
+// revoked note from an expired or used up one
+func noteUnavailable(c *gin.Context, status string) {
+	messages := map[string]string{
+		models.NoteRevoked:   "Note has been revoked by its owner",
+		models.NoteExpired:   "Note has expired",
+		models.NoteExhausted: "Note has reached its view limit",
+		models.NoteDestroyed: "Note was destroyed after too many wrong access keys",
+	}
+	c.JSON(http.StatusGone, gin.H{"success": false, "message": messages[status], "data": gin.H{"status": status}})
+}
+
+func viewsRemaining(n models.Note) *int {
+	if n.MaxViews == nil {
+		return nil
+	}
+	left := *n.MaxViews - n.ViewCount
+	if left < 0 {
+		left = 0
+	}
+	return &left
+}
+
+// PATCH /api/notes/:slug
+func UpdateNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Envelope       *noteenvelope.Envelope `json:"envelope"`
+		CipherNote     *string                `json:"ciphertext"`
+		ExpiresAt      *time.Time             `json:"expiresAt,omitempty"`
+		ClearExpiry    bool                   `json:"clearExpiry"`
+		MaxViews       *int                   `json:"maxViews,omitempty"`
+		ClearMaxViews  bool                   `json:"clearMaxViews"`
+		NotifyOnView   *bool                  `json:"notifyOnView"`
+		Revoke         bool                   `json:"revoke"`
+		AccessKey      *string                `json:"accessKey"`
+		MaxAttempts    *int                   `json:"maxAttempts,omitempty"`
+		ClearAccessKey bool                   `json:"clearAccessKey"`
+		Slug           *string                `json:"slug"`
+		Title          *string                `json:"title"`
+		FolderID       *uuid.UUID             `json:"folderId,omitempty"`
+		ClearFolder    bool                   `json:"clearFolder"`
+		Labels         *[]string              `json:"labels"`
+		NotBefore      *time.Time             `json:"notBefore,omitempty"`
+		ClearNotBefore bool                   `json:"clearNotBefore"`
+		Publish        bool                   `json:"publish"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	var note models.Note
+	if err := repositories.DB.Preload("Labels").Where("slug = ? AND user_id = ?", c.Param("slug"), userID).First(&note).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch note"})
+		}
+		return
+	}
+
+	if note.RevokedAt != nil || note.DestroyedAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Revoked or destroyed notes cannot be changed"})
+		return
+	}
+
+	// Only the columns the request changes are written, so reads, failed
+	// unlock attempts and burns since the note was loaded are kept
+	now := time.Now()
+	updates := map[string]interface{}{}
+	if input.Revoke {
+		// Revocation is final, the ciphertext is wiped rather than hidden
+		note.RevokedAt = &now
+		updates = note.Wipe()
+		updates["revoked_at"] = now
+	} else {
+		if input.Envelope != nil || input.CipherNote != nil {
+			legacy := ""
+			if input.CipherNote != nil {
+				legacy = *input.CipherNote
+			}
+			envelope, msg := parseNoteEnvelope(input.Envelope, legacy)
+			if msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+				return
+			}
+			setNoteEnvelope(&note, envelope)
+			for column, value := range noteEnvelopeColumns(note) {
+				updates[column] = value
+			}
+		}
+		if input.ExpiresAt != nil {
+			if !input.ExpiresAt.After(now) {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Expiry must be in the future"})
+				return
+			}
+			note.ExpiresAt = input.ExpiresAt
+		}
+		if input.ClearExpiry {
+			note.ExpiresAt = nil
+		}
+		if input.ExpiresAt != nil || input.ClearExpiry {
+			note.ExpiryAnnounced = false
+			updates["expires_at"] = note.ExpiresAt
+			updates["expiry_announced"] = false
+		}
+		if input.NotBefore != nil {
+			note.NotBefore = input.NotBefore
+		}
+		if input.ClearNotBefore {
+			note.NotBefore = nil
+		}
+		if input.NotBefore != nil || input.ClearNotBefore {
+			updates["not_before"] = note.NotBefore
+		}
+		if msg := checkNoteSchedule(note.NotBefore, note.ExpiresAt); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+			return
+		}
+		// Publishing is one way, published notes never go back to draft
+		if input.Publish {
+			note.Draft = false
+			updates["draft"] = false
+		}
+		if input.MaxViews != nil {
+			if *input.MaxViews < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Max views must be at least 1"})
+				return
+			}
+			note.MaxViews = input.MaxViews
+		}
+		if input.ClearMaxViews {
+			note.MaxViews = nil
+		}
+		if input.MaxViews != nil || input.ClearMaxViews {
+			updates["max_views"] = note.MaxViews
+		}
+		if input.NotifyOnView != nil {
+			note.NotifyOnView = *input.NotifyOnView
+			updates["notify_on_view"] = note.NotifyOnView
+		}
+		if input.Slug != nil && *input.Slug != note.Slug {
+			if msg := checkCustomSlug(*input.Slug); msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+				return
+			}
+			note.Slug = *input.Slug
+			updates["slug"] = note.Slug
+		}
+		if input.Title != nil {
+			note.Title = *input.Title
+			updates["title"] = note.Title
+		}
+		if input.FolderID != nil {
+			note.FolderID = input.FolderID
+		}
+		if input.ClearFolder {
+			note.FolderID = nil
+		}
+		if input.FolderID != nil || input.ClearFolder {
+			updates["folder_id"] = note.FolderID
+		}
+		if input.Labels != nil {
+			labels, msg := noteLabels(*input.Labels)
+			if msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+				return
+			}
+			note.Labels = labels
+		}
+		if msg := checkNoteMetadata(userID, note.Title, input.FolderID); msg != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+			return
+		}
+		if input.ClearAccessKey {
+			note.AccessKeyHash = nil
+			note.FailedAttempts = 0
+			note.MaxAttempts = 0
+		}
+		if input.AccessKey != nil || input.MaxAttempts != nil {
+			key := ""
+			if input.AccessKey != nil {
+				key = *input.AccessKey
+			}
+			if msg := setAccessKey(&note, key, input.MaxAttempts); msg != "" {
+				c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+				return
+			}
+		}
+		// Failed attempts only start over with a new key
+		if input.ClearAccessKey || (input.AccessKey != nil && *input.AccessKey != "") {
+			updates["access_key_hash"] = note.AccessKeyHash
+			updates["failed_attempts"] = note.FailedAttempts
+		}
+		if input.ClearAccessKey || input.AccessKey != nil || input.MaxAttempts != nil {
+			updates["max_attempts"] = note.MaxAttempts
+		}
+	}
+
+	err := repositories.DB.Transaction(func(tx *gorm.DB) error {
+		// The note may have been revoked or burnt since it was loaded. The
+		// update, or the lock when only labels change, holds the row until
+		// the labels are written.
+		live := tx.Model(&models.Note{}).Where("id = ? AND revoked_at IS NULL AND destroyed_at IS NULL", note.ID)
+		var result *gorm.DB
+		if len(updates) > 0 {
+			result = live.Updates(updates)
+		} else {
+			result = live.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Find(&models.Note{})
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errNoteGone
+		}
+
+		if input.Labels != nil {
+			if err := tx.Where("note_id = ?", note.ID).Delete(&models.NoteLabel{}).Error; err != nil {
+				return err
+			}
+			for i := range note.Labels {
+				note.Labels[i].NoteID = note.ID
+			}
+			if len(note.Labels) > 0 {
+				if err := tx.Create(&note.Labels).Error; err != nil {
+					return err
+				}
+			}
+		}
+		// Answer with the note as stored, counters included
+		var fresh models.Note
+		if err := tx.Preload("Labels").Where("id = ?", note.ID).First(&fresh).Error; err != nil {
+			return err
+		}
+		note = fresh
+		return nil
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errNoteGone):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Revoked or destroyed notes cannot be changed"})
+		case errors.Is(err, gorm.ErrDuplicatedKey):
+			c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Slug is already taken"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update note"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note updated successfully", "data": noteSummary(note, now)})
+}
+
+var errNoteGone = errors.New("note was revoked or destroyed")
+
+// noteSummary describes a note to whoever manages it, without the ciphertext
+func noteSummary(n models.Note, now time.Time) gin.H {
+	labels := make([]string, 0, len(n.Labels))
+	for _, l := range n.Labels {
+		labels = append(labels, l.Label)
+	}
+	return gin.H{
+		"id":                n.ID,
+		"slug":              n.Slug,
+		"title":             n.Title,
+		"folderId":          n.FolderID,
+		"labels":            labels,
+		"status":            n.Status(now),
+		"createdAt":         n.CreatedAt,
+		"expiresAt":         n.ExpiresAt,
+		"maxViews":          n.MaxViews,
+		"viewCount":         n.ViewCount,
+		"viewsRemaining":    viewsRemaining(n),
+		"revokedAt":         n.RevokedAt,
+		"firstViewedAt":     n.FirstViewedAt,
+		"notifyOnView":      n.NotifyOnView,
+		"recipientsOnly":    n.RecipientsOnly,
+		"draft":             n.Draft,
+		"notBefore":         n.NotBefore,
+		"gated":             n.Gated(),
+		"failedAttempts":    n.FailedAttempts,
+		"attemptsRemaining": attemptsRemaining(n),
+		"destroyedAt":       n.DestroyedAt,
+	}
+}
+
+// DELETE /api/notes/:slug
+func DeleteNote(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	result := repositories.DB.Where("slug = ? AND user_id = ?", c.Param("slug"), userID).Delete(&models.Note{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete note"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Note deleted successfully"})
+}
+
+// publishNoteEvent tells the owner's webhooks what happened to the note
+func (h *NoteHandler) publishNoteEvent(eventType string, n models.Note) {
+	if n.UserID == nil {
+		return
+	}
+	event, err := events.New(eventType, *n.UserID, events.NewNoteData(n))
+	if err == nil {
+		err = h.events.Publish(event)
+	}
+	if err != nil {
+		log.Printf("Failed to publish %s for note %s: %v\n", eventType, n.ID, err)
+	}
+}
+
+// recordNoteView adds the read to the access log and reports whether it was
+// the first one. A failed insert only costs the log entry.
+func recordNoteView(c *gin.Context, n *models.Note) bool {
+	view := models.NoteView{
+		NoteID:    n.ID,
+		IPHash:    utils.ViewerFingerprint(config.Envs.FingerprintSalt, n.ID.String(), c.ClientIP()),
+		UserAgent: utils.UserAgentFamily(c.Request.UserAgent()),
+	}
+	if header := config.Envs.GeoCountryHeader; header != "" {
+		if country := strings.ToUpper(c.GetHeader(header)); len(country) == 2 {
+			view.Country = country
+		}
+	}
+	if err := repositories.DB.Create(&view).Error; err != nil {
+		log.Printf("Failed to record view of note %s: %v\n", n.ID, err)
+	}
+
+	// Concurrent readers race for the first view, only one claims it
+	now := time.Now()
+	result := repositories.DB.Model(&models.Note{}).
+		Where("id = ? AND first_viewed_at IS NULL", n.ID).
+		UpdateColumn("first_viewed_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	n.FirstViewedAt = &now
+	return true
+}
+
+// GET /api/notes/:slug/views?page=1&limit=50
+func GetNoteViews(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var note models.Note
+	if err := repositories.DB.Where("slug = ? AND user_id = ?", c.Param("slug"), userID).First(&note).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Note not found"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxNoteViewsPageSize {
+		limit = maxNoteViewsPageSize
+	}
+
+	var views []models.NoteView
+	err := repositories.DB.Where("note_id = ?", note.ID).
+		Order("viewed_at desc").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&views).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch views"})
+		return
+	}
+
+	var uniqueViewers int64
+	repositories.DB.Model(&models.NoteView{}).Where("note_id = ?", note.ID).Distinct("ip_hash").Count(&uniqueViewers)
+
 	c.JSON(http.StatusOK, gin.H{
 		"success": true,
-		"message": "Note fetched successfully",
+		"message": "Views fetched successfully",
 		"data": gin.H{
-			"ciphernote": note.CipherNote,
-			"createdAt":  note.CreatedAt,
-			"expiresAt":  note.ExpiresAt,
+			"viewCount":     note.ViewCount,
+			"uniqueViewers": uniqueViewers,
+			"firstViewedAt": note.FirstViewedAt,
+			"page":          page,
+			"limit":         limit,
+			"items":         views,
 		},
 	})
 }
 
-// GET /notes/user/:userId
+// notesSortColumns maps the sort options of the note listing to columns
+var notesSortColumns = map[string]string{
+	"createdAt": "created_at",
+	"expiresAt": "expires_at",
+	"viewCount": "view_count",
+}
+
+// GET /api/notes/user/:userId?folder=<id|none>&label=a&label=b&status=active&sort=createdAt&order=desc&page=1&limit=20&include=ciphertext
+// Lists the caller's notes. Ciphertext is left out unless asked for, so the
+// dashboard stays fast for owners with many notes.
 func GetUserNotes(c *gin.Context) {
-	userIdParam := c.Param("userId")
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
 
-	var notes []models.Note
+	uid, err := parseUserID(userID)
+	if err != nil || c.Param("userId") != uid.String() {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "You can only list your own notes"})
+		return
+	}
+
+	now := time.Now()
+	query := repositories.DB.Model(&models.Note{}).Where("user_id = ?", uid)
+
+	switch folder := c.Query("folder"); folder {
+	case "":
+	case "none":
+		query = query.Where("folder_id IS NULL")
+	default:
+		if _, err := uuid.Parse(folder); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid folder"})
+			return
+		}
+		query = query.Where("folder_id = ?", folder)
+	}
+
+	for _, label := range c.QueryArray("label") {
+		query = query.Where("EXISTS (SELECT 1 FROM note_labels WHERE note_labels.note_id = notes.id AND note_labels.label = ?)",
+			strings.ToLower(strings.TrimSpace(label)))
+	}
+
+	if status := c.Query("status"); status != "" {
+		var ok bool
+		if query, ok = filterNoteStatus(query, status, now); !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid status"})
+			return
+		}
+	}
+
+	column, ok := notesSortColumns[c.DefaultQuery("sort", "createdAt")]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Sort must be one of createdAt, expiresAt or viewCount"})
+		return
+	}
+	desc := c.DefaultQuery("order", "desc") != "asc"
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxNotesPageSize {
+		limit = maxNotesPageSize
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		log.Printf("Error counting notes for user %s: %v\n", uid, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to query notes"})
+		return
+	}
 
-	if err := repositories.DB.Where("user_id = ?", userIdParam).Find(&notes).Error; err != nil {
-		log.Printf("Error finding notes for user %s: %v\n", userIdParam, err)
+	withCiphertext := c.Query("include") == "ciphertext"
+	if !withCiphertext {
+		query = query.Omit("cipher_note")
+	}
+
+	var notes []models.Note
+	err = query.Preload("Labels").
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc}).
+		Order("id").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&notes).Error
+	if err != nil {
+		log.Printf("Error finding notes for user %s: %v\n", uid, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to query notes"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Notes fetched successfully", "data": notes})
+	items := make([]gin.H, 0, len(notes))
+	for _, n := range notes {
+		item := noteSummary(n, now)
+		if withCiphertext {
+			envelope, ok := noteEnvelope(n)
+			item["envelope"] = envelopeOrNil(envelope, ok)
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Notes fetched successfully",
+		"data": gin.H{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+			"items": items,
+		},
+	})
+}
+
+// filterNoteStatus narrows query to notes that Note.Status would report as
+// status at now
+func filterNoteStatus(query *gorm.DB, status string, now time.Time) (*gorm.DB, bool) {
+	const (
+		live      = "destroyed_at IS NULL AND revoked_at IS NULL"
+		unexpired = live + " AND (expires_at IS NULL OR expires_at > ?)"
+		readable  = unexpired + " AND (max_views IS NULL OR view_count < max_views)"
+		published = readable + " AND NOT draft"
+	)
+	switch status {
+	case models.NoteDestroyed:
+		return query.Where("destroyed_at IS NOT NULL"), true
+	case models.NoteRevoked:
+		return query.Where("destroyed_at IS NULL AND revoked_at IS NOT NULL"), true
+	case models.NoteExpired:
+		return query.Where(live+" AND expires_at <= ?", now), true
+	case models.NoteExhausted:
+		return query.Where(unexpired+" AND max_views IS NOT NULL AND view_count >= max_views", now), true
+	case models.NoteDraft:
+		return query.Where(readable+" AND draft", now), true
+	case models.NoteScheduled:
+		return query.Where(published+" AND not_before > ?", now, now), true
+	case models.NoteActive:
+		return query.Where(published+" AND (not_before IS NULL OR not_before <= ?)", now, now), true
+	}
+	return query, false
+}
+
+// noteLabels normalises the labels of a note, returning an error message when
+// there are too many or one is invalid
+func noteLabels(raw []string) ([]models.NoteLabel, string) {
+	names := make([]string, 0, len(raw))
+	for _, l := range raw {
+		l = strings.ToLower(strings.TrimSpace(l))
+		if l == "" || utf8.RuneCountInString(l) > maxNoteLabelLength {
+			return nil, fmt.Sprintf("Labels must be between 1 and %d characters", maxNoteLabelLength)
+		}
+		names = append(names, l)
+	}
+	names = dedupe(names)
+	if len(names) > maxNoteLabels {
+		return nil, fmt.Sprintf("Notes can have at most %d labels", maxNoteLabels)
+	}
+
+	labels := make([]models.NoteLabel, 0, len(names))
+	for _, l := range names {
+		labels = append(labels, models.NoteLabel{Label: l})
+	}
+	return labels, ""
+}
+
+// checkNoteMetadata validates an encrypted title and that folderID, if set,
+// is one of the owner's folders
+func checkNoteMetadata(userID any, title string, folderID *uuid.UUID) string {
+	if len(title) > maxNoteTitleSize {
+		return fmt.Sprintf("Encrypted title must be at most %d bytes", maxNoteTitleSize)
+	}
+	if folderID != nil {
+		var count int64
+		repositories.DB.Model(&models.NoteFolder{}).Where("id = ? AND user_id = ?", *folderID, userID).Count(&count)
+		if count == 0 {
+			return "Folder not found"
+		}
+	}
+	return ""
 }
 
 // GET /
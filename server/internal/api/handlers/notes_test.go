@@ -0,0 +1,338 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/noteenvelope"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// createTestNote stores a note of owner with a random key envelope
+func createTestNote(t *testing.T, owner models.User, opts ...func(n *models.Note)) models.Note {
+	t.Helper()
+	n := models.Note{
+		Slug:            "t" + uuid.NewString()[:12],
+		UserID:          &owner.ID,
+		EnvelopeVersion: noteenvelope.CurrentVersion,
+		KDF:             noteenvelope.KDFNone,
+		IV:              "AAAAAAAAAAAAAAAA",
+		CipherNote:      "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+		CreatedAt:       time.Now(),
+	}
+	for _, opt := range opts {
+		opt(&n)
+	}
+	if err := repositories.DB.Create(&n).Error; err != nil {
+		t.Fatalf("create note: %v", err)
+	}
+	return n
+}
+
+// afterNoteLoad runs fn once, right after the first query on notes, to act
+// out a request racing the one under test
+func afterNoteLoad(t *testing.T, fn func()) {
+	t.Helper()
+	const name = "test:after_note_load"
+	fired := false
+	err := repositories.DB.Callback().Query().After("gorm:query").Register(name, func(db *gorm.DB) {
+		if !fired && db.Statement.Table == "notes" {
+			fired = true
+			fn()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { repositories.DB.Callback().Query().Remove(name) })
+}
+
+func reloadNote(t *testing.T, id uuid.UUID) models.Note {
+	t.Helper()
+	var n models.Note
+	if err := repositories.DB.Where("id = ?", id).First(&n).Error; err != nil {
+		t.Fatalf("reload note: %v", err)
+	}
+	return n
+}
+
+func updateNoteRoute(owner models.User) func(r *gin.Engine) {
+	return func(r *gin.Engine) { r.PATCH("/notes/:slug", asUser(owner), UpdateNote) }
+}
+
+func TestUpdateNoteKeepsConcurrentReads(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	one := 1
+	note := createTestNote(t, owner, func(n *models.Note) { n.MaxViews = &one })
+
+	// The only read allowed lands while the owner renames the note
+	afterNoteLoad(t, func() {
+		repositories.DB.Exec("UPDATE notes SET view_count = view_count + 1 WHERE id = ?", note.ID)
+	})
+
+	code, resp := serve(t, updateNoteRoute(owner), http.MethodPatch, "/notes/"+note.Slug, gin.H{"title": "cmVuYW1lZA=="})
+	if code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", code, resp.Message)
+	}
+
+	got := reloadNote(t, note.ID)
+	if got.ViewCount != 1 {
+		t.Errorf("view count = %d, the read was overwritten", got.ViewCount)
+	}
+	if got.Title != "cmVuYW1lZA==" {
+		t.Errorf("title = %q", got.Title)
+	}
+}
+
+func TestUpdateNoteRefusesNotesBurntMeanwhile(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner)
+
+	afterNoteLoad(t, func() {
+		repositories.DB.Model(&models.Note{}).Where("id = ?", note.ID).
+			Updates(map[string]interface{}{"destroyed_at": time.Now(), "cipher_note": "", "salt": "", "iv": "", "failed_attempts": 5})
+	})
+
+	code, _ := serve(t, updateNoteRoute(owner), http.MethodPatch, "/notes/"+note.Slug, gin.H{"title": "bmV3"})
+	if code != http.StatusConflict {
+		t.Fatalf("PATCH = %d, want 409", code)
+	}
+
+	got := reloadNote(t, note.ID)
+	if got.CipherNote != "" || got.IV != "" || got.DestroyedAt == nil || got.FailedAttempts != 5 {
+		t.Errorf("burnt note was written back: %+v", got)
+	}
+}
+
+func TestUpdateNoteKeepsFailedAttempts(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner)
+
+	afterNoteLoad(t, func() {
+		repositories.DB.Exec("UPDATE notes SET failed_attempts = failed_attempts + 1 WHERE id = ?", note.ID)
+	})
+
+	code, resp := serve(t, updateNoteRoute(owner), http.MethodPatch, "/notes/"+note.Slug, gin.H{"notifyOnView": true})
+	if code != http.StatusOK {
+		t.Fatalf("PATCH = %d %s", code, resp.Message)
+	}
+	if got := reloadNote(t, note.ID); got.FailedAttempts != 1 || !got.NotifyOnView {
+		t.Errorf("failed attempts = %d, notify = %v", got.FailedAttempts, got.NotifyOnView)
+	}
+}
+
+func readNoteRoutes(r *gin.Engine) {
+	h := NewNoteHandler(nil)
+	r.GET("/notes/:slug", h.GetNote)
+	r.POST("/notes/:slug/unlock", h.UnlockNote)
+}
+
+// testAccessKey is the base64 key a reader derives from the note password
+var testAccessKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+
+// gated protects a test note with testAccessKey and maxAttempts tries
+func gated(maxAttempts int) func(n *models.Note) {
+	return func(n *models.Note) {
+		hash, err := noteenvelope.HashAccessKey(testAccessKey)
+		if err != nil {
+			panic(err)
+		}
+		n.AccessKeyHash = &hash
+		n.MaxAttempts = maxAttempts
+	}
+}
+
+type noteData struct {
+	Status            string `json:"status"`
+	RequiresAccessKey bool   `json:"requiresAccessKey"`
+	CipherNote        string `json:"ciphernote"`
+	Envelope          *struct {
+		Ciphertext string `json:"ciphertext"`
+	} `json:"envelope"`
+	AttemptsRemaining *int `json:"attemptsRemaining"`
+}
+
+func decodeNote(t *testing.T, resp testResponse) noteData {
+	t.Helper()
+	var d noteData
+	if len(resp.Data) > 0 {
+		if err := json.Unmarshal(resp.Data, &d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return d
+}
+
+func TestGetNoteWithholdsGatedCiphertext(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner, gated(3))
+
+	code, resp := serve(t, readNoteRoutes, http.MethodGet, "/notes/"+note.Slug, nil)
+	if code != http.StatusOK {
+		t.Fatalf("GET = %d %s", code, resp.Message)
+	}
+	d := decodeNote(t, resp)
+	if !d.RequiresAccessKey || d.CipherNote != "" || (d.Envelope != nil && d.Envelope.Ciphertext != "") {
+		t.Errorf("locked note = %s", resp.Data)
+	}
+	if d.AttemptsRemaining == nil || *d.AttemptsRemaining != 3 {
+		t.Errorf("attempts remaining = %v", d.AttemptsRemaining)
+	}
+	if got := reloadNote(t, note.ID); got.ViewCount != 0 {
+		t.Errorf("locked read counted as a view")
+	}
+}
+
+func TestUnlockNote(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner, gated(3), func(n *models.Note) { n.FailedAttempts = 2 })
+
+	code, resp := serve(t, readNoteRoutes, http.MethodPost, "/notes/"+note.Slug+"/unlock", gin.H{"accessKey": testAccessKey})
+	if code != http.StatusOK {
+		t.Fatalf("unlock = %d %s", code, resp.Message)
+	}
+	if d := decodeNote(t, resp); d.Envelope == nil || d.Envelope.Ciphertext != note.CipherNote {
+		t.Errorf("unlocked note = %s", resp.Data)
+	}
+	got := reloadNote(t, note.ID)
+	if got.ViewCount != 1 || got.FailedAttempts != 0 {
+		t.Errorf("view count %d, failed attempts %d after unlocking", got.ViewCount, got.FailedAttempts)
+	}
+}
+
+func TestUnlockNoteDestroysAfterMaxAttempts(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner, gated(2))
+	wrong := gin.H{"accessKey": base64.StdEncoding.EncodeToString([]byte("not the right key, not at all"))}
+
+	code, resp := serve(t, readNoteRoutes, http.MethodPost, "/notes/"+note.Slug+"/unlock", wrong)
+	if d := decodeNote(t, resp); code != http.StatusForbidden || d.AttemptsRemaining == nil || *d.AttemptsRemaining != 1 {
+		t.Fatalf("first wrong key = %d %s", code, resp.Data)
+	}
+
+	code, resp = serve(t, readNoteRoutes, http.MethodPost, "/notes/"+note.Slug+"/unlock", wrong)
+	if d := decodeNote(t, resp); code != http.StatusGone || d.Status != models.NoteDestroyed {
+		t.Fatalf("last wrong key = %d %s", code, resp.Data)
+	}
+	got := reloadNote(t, note.ID)
+	if got.DestroyedAt == nil || got.CipherNote != "" || got.IV != "" {
+		t.Errorf("destroyed note kept its ciphertext: %+v", got)
+	}
+
+	// Not even the right key opens it now
+	code, _ = serve(t, readNoteRoutes, http.MethodPost, "/notes/"+note.Slug+"/unlock", gin.H{"accessKey": testAccessKey})
+	if code != http.StatusGone {
+		t.Errorf("unlock after destruction = %d, want 410", code)
+	}
+}
+
+func TestUnlockNoteRefuses(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+
+	tests := []struct {
+		name string
+		opts []func(n *models.Note)
+		body gin.H
+		want int
+	}{
+		{"note without a key", nil, gin.H{"accessKey": testAccessKey}, http.StatusBadRequest},
+		{"no key given", []func(n *models.Note){gated(3)}, gin.H{}, http.StatusBadRequest},
+		{"recipients only", []func(n *models.Note){gated(3), func(n *models.Note) { n.RecipientsOnly = true }}, gin.H{"accessKey": testAccessKey}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			note := createTestNote(t, owner, tt.opts...)
+			code, resp := serve(t, readNoteRoutes, http.MethodPost, "/notes/"+note.Slug+"/unlock", tt.body)
+			if code != tt.want {
+				t.Errorf("unlock = %d %s, want %d", code, resp.Message, tt.want)
+			}
+			if got := reloadNote(t, note.ID); got.ViewCount != 0 {
+				t.Error("refused unlock counted as a view")
+			}
+		})
+	}
+}
+
+func TestGetNoteHidesDrafts(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	note := createTestNote(t, owner, func(n *models.Note) { n.Draft = true })
+
+	code, resp := serve(t, readNoteRoutes, http.MethodGet, "/notes/"+note.Slug, nil)
+	if code != http.StatusNotFound || resp.Message != "Note not found" || len(resp.Data) != 0 {
+		t.Errorf("draft = %d %q %s, want the answer for a missing note", code, resp.Message, resp.Data)
+	}
+
+	// Published drafts are readable, and stay published
+	code, resp = serve(t, updateNoteRoute(owner), http.MethodPatch, "/notes/"+note.Slug, gin.H{"publish": true})
+	if code != http.StatusOK {
+		t.Fatalf("publish = %d %s", code, resp.Message)
+	}
+	if code, resp = serve(t, readNoteRoutes, http.MethodGet, "/notes/"+note.Slug, nil); code != http.StatusOK {
+		t.Errorf("published note = %d %s", code, resp.Message)
+	}
+	if got := reloadNote(t, note.ID); got.Draft || got.ViewCount != 1 {
+		t.Errorf("draft %v, view count %d after publishing and one read", got.Draft, got.ViewCount)
+	}
+}
+
+func TestGetNoteHoldsScheduledNotes(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	release := time.Now().Add(time.Hour).Truncate(time.Second)
+	note := createTestNote(t, owner, func(n *models.Note) { n.NotBefore = &release })
+
+	code, resp := serve(t, readNoteRoutes, http.MethodGet, "/notes/"+note.Slug, nil)
+	if code != http.StatusTooEarly {
+		t.Fatalf("scheduled note = %d %s, want 425", code, resp.Message)
+	}
+	var data struct {
+		Status    string    `json:"status"`
+		NotBefore time.Time `json:"notBefore"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if data.Status != models.NoteScheduled || !data.NotBefore.Equal(release) {
+		t.Errorf("scheduled note = %s", resp.Data)
+	}
+	if code, _ := serve(t, readNoteRoutes, http.MethodPost, "/notes/"+note.Slug+"/unlock", gin.H{"accessKey": testAccessKey}); code != http.StatusTooEarly {
+		t.Errorf("unlock before release = %d, want 425", code)
+	}
+
+	// Once the release time has passed the note reads normally
+	past := time.Now().Add(-time.Minute)
+	repositories.DB.Model(&models.Note{}).Where("id = ?", note.ID).Update("not_before", past)
+	if code, resp := serve(t, readNoteRoutes, http.MethodGet, "/notes/"+note.Slug, nil); code != http.StatusOK {
+		t.Errorf("released note = %d %s", code, resp.Message)
+	}
+}
+
+func TestUpdateNoteRejectsReleaseAfterExpiry(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	expires := time.Now().Add(time.Hour)
+	note := createTestNote(t, owner, func(n *models.Note) { n.ExpiresAt = &expires })
+
+	code, _ := serve(t, updateNoteRoute(owner), http.MethodPatch, "/notes/"+note.Slug, gin.H{"notBefore": expires.Add(time.Minute)})
+	if code != http.StatusBadRequest {
+		t.Errorf("release after expiry = %d, want 400", code)
+	}
+	if got := reloadNote(t, note.ID); got.NotBefore != nil {
+		t.Errorf("not before = %v", got.NotBefore)
+	}
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/notify"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// unsubscribeKey verifies the unsubscribe links the worker puts in emails
+var unsubscribeKey = config.TokenKey("unsubscribe")
+
+// GET /api/user/notifications
+func GetNotificationSettings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch notification settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Notification settings fetched successfully", "data": gin.H{"notifyMode": user.NotifyMode}})
+}
+
+// PATCH /api/user/notifications
+func UpdateNotificationSettings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		NotifyMode string `json:"notifyMode"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || !notify.ValidMode(input.NotifyMode) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "notifyMode must be one of instant, hourly, daily, off"})
+		return
+	}
+
+	// Digests start counting from now rather than replaying older messages
+	result := repositories.DB.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"notify_mode": input.NotifyMode, "last_notified_at": gorm.Expr("NOW()")})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update notification settings"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Notification settings updated"})
+}
+
+// GET|POST /api/notifications/unsubscribe?token=...
+func Unsubscribe(c *gin.Context) {
+	userID, err := notify.VerifyUnsubscribeToken(unsubscribeKey, c.Query("token"), time.Now())
+	if errors.Is(err, notify.ErrExpiredToken) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "This unsubscribe link has expired, change notifications in your settings instead"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid unsubscribe link"})
+		return
+	}
+
+	result := repositories.DB.Model(&models.User{}).Where("id = ?", userID).Update("notify_mode", notify.ModeOff)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to unsubscribe"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "You will no longer receive message notifications"})
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	maxPromptTitleLength    = 80
+	maxPromptQuestionLength = 500
+)
+
+var promptSlugPattern = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]{1,46}[a-z0-9])$`)
+
+// GET /api/prompts
+func GetPrompts(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var prompts []models.Prompt
+	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&prompts).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch prompts"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Prompts fetched successfully", "data": prompts})
+}
+
+// POST /api/prompts
+func CreatePrompt(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Title     string     `json:"title"`
+		Slug      string     `json:"slug"`
+		Question  string     `json:"question"`
+		ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	prompt := models.Prompt{
+		UserID:    uid,
+		Title:     strings.TrimSpace(input.Title),
+		Question:  strings.TrimSpace(input.Question),
+		IsOpen:    true,
+		ExpiresAt: input.ExpiresAt,
+	}
+	if msg := validatePrompt(prompt); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	prompt.Slug = strings.ToLower(strings.TrimSpace(input.Slug))
+	if prompt.Slug == "" {
+		prompt.Slug = slugify(prompt.Title)
+	} else if !promptSlugPattern.MatchString(prompt.Slug) {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Slug must be 3-48 lowercase letters, digits or hyphens"})
+		return
+	}
+
+	var taken int64
+	repositories.DB.Model(&models.Prompt{}).Where("slug = ?", prompt.Slug).Count(&taken)
+	if taken > 0 {
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Slug is already taken"})
+		return
+	}
+
+	if err := repositories.DB.Create(&prompt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create prompt"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Prompt created successfully", "data": prompt})
+}
+
+// PATCH /api/prompts/:id
+func UpdatePrompt(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Title       *string    `json:"title"`
+		Question    *string    `json:"question"`
+		IsOpen      *bool      `json:"isOpen"`
+		ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+		ClearExpiry bool       `json:"clearExpiry"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	var prompt models.Prompt
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&prompt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Prompt not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch prompt"})
+		}
+		return
+	}
+
+	if input.Title != nil {
+		prompt.Title = strings.TrimSpace(*input.Title)
+	}
+	if input.Question != nil {
+		prompt.Question = strings.TrimSpace(*input.Question)
+	}
+	if input.IsOpen != nil {
+		prompt.IsOpen = *input.IsOpen
+	}
+	if input.ExpiresAt != nil {
+		prompt.ExpiresAt = input.ExpiresAt
+	}
+	if input.ClearExpiry {
+		prompt.ExpiresAt = nil
+	}
+	if msg := validatePrompt(prompt); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	if err := repositories.DB.Save(&prompt).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update prompt"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Prompt updated successfully", "data": prompt})
+}
+
+// DELETE /api/prompts/:id
+func DeletePrompt(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	// Messages received through the prompt stay in the inbox without it
+	result := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.Prompt{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete prompt"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Prompt not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Prompt deleted successfully"})
+}
+
+// GET /api/prompts/public/:slug
+func GetPublicPrompt(c *gin.Context) {
+	var prompt models.Prompt
+	if err := repositories.DB.Preload("User").Where("slug = ?", c.Param("slug")).First(&prompt).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Prompt not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Prompt fetched successfully",
+		"data": gin.H{
+			"slug":      prompt.Slug,
+			"title":     prompt.Title,
+			"question":  prompt.Question,
+			"username":  prompt.User.Username,
+			"isOpen":    prompt.AcceptsMessages(time.Now()) && prompt.User.IsAcceptingMessages,
+			"expiresAt": prompt.ExpiresAt,
+		},
+	})
+}
+
+// findPromptBySlug resolves the prompt a message is sent through
+func findPromptBySlug(slug string) (*models.Prompt, error) {
+	var prompt models.Prompt
+	if err := repositories.DB.Where("slug = ?", slug).First(&prompt).Error; err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// findPromptFilter resolves the ?prompt= filter of GetMessages, by ID or slug
+func findPromptFilter(userID interface{}, ref string) (*models.Prompt, error) {
+	query := repositories.DB.Where("user_id = ?", userID)
+	if _, err := uuid.Parse(ref); err == nil {
+		query = query.Where("id = ?", ref)
+	} else {
+		query = query.Where("slug = ?", ref)
+	}
+
+	var prompt models.Prompt
+	if err := query.First(&prompt).Error; err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+func validatePrompt(p models.Prompt) string {
+	switch {
+	case p.Title == "" || len(p.Title) > maxPromptTitleLength:
+		return fmt.Sprintf("Title must be between 1 and %d characters", maxPromptTitleLength)
+	case len(p.Question) > maxPromptQuestionLength:
+		return fmt.Sprintf("Question must be at most %d characters", maxPromptQuestionLength)
+	}
+	return ""
+}
+
+// slugify derives a public slug from a title, with a random suffix so
+// common titles like "Ask me anything" don't collide between users
+func slugify(title string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(title) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			dash = false
+		case !dash && b.Len() > 0:
+			b.WriteByte('-')
+			dash = true
+		}
+		if b.Len() >= 32 {
+			break
+		}
+	}
+	base := strings.Trim(b.String(), "-")
+	if base == "" {
+		base = "prompt"
+	}
+	return base + "-" + uuid.NewString()[:8]
+}
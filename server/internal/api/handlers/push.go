@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+	"github.com/rohits-web03/SilentEcho/server/internal/webpush"
+	"gorm.io/gorm/clause"
+)
+
+const maxPushSubscriptionsPerUser = 20
+
+// GET /api/push/vapid-public-key
+// The browser needs it as applicationServerKey before subscribing
+func GetVAPIDPublicKey(c *gin.Context) {
+	if config.Envs.VAPIDPublicKey == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "message": "Push notifications are not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "VAPID public key fetched successfully", "data": gin.H{"publicKey": config.Envs.VAPIDPublicKey}})
+}
+
+// GET /api/push/subscriptions
+func GetPushSubscriptions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var subs []models.PushSubscription
+	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&subs).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch push subscriptions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Push subscriptions fetched successfully", "data": subs})
+}
+
+// POST /api/push/subscriptions
+// Accepts PushSubscription.toJSON() as sent by the browser
+func SubscribePush(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	if config.Envs.VAPIDPublicKey == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"success": false, "message": "Push notifications are not configured"})
+		return
+	}
+
+	var input struct {
+		Endpoint string `json:"endpoint"`
+		Keys     struct {
+			P256dh string `json:"p256dh"`
+			Auth   string `json:"auth"`
+		} `json:"keys"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	sub := webpush.Subscription{
+		Endpoint: strings.TrimSpace(input.Endpoint),
+		P256dh:   input.Keys.P256dh,
+		Auth:     input.Keys.Auth,
+	}
+	if err := webhook.ValidateURL(sub.Endpoint, config.Envs.AllowPrivateWebhooks); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid push endpoint"})
+		return
+	}
+	if err := sub.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid push subscription keys"})
+		return
+	}
+
+	var count int64
+	repositories.DB.Model(&models.PushSubscription{}).Where("user_id = ? AND endpoint <> ?", uid, sub.Endpoint).Count(&count)
+	if count >= maxPushSubscriptionsPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Too many devices registered for push"})
+		return
+	}
+
+	// Browsers resubscribe with the same endpoint, which may now belong to
+	// whoever logged in on that device
+	record := models.PushSubscription{
+		UserID:    uid,
+		Endpoint:  sub.Endpoint,
+		P256dh:    sub.P256dh,
+		Auth:      sub.Auth,
+		UserAgent: c.Request.UserAgent(),
+	}
+	err = repositories.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent"}),
+	}).Create(&record).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to save push subscription"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Push notifications enabled"})
+}
+
+// DELETE /api/push/subscriptions
+func UnsubscribePush(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Endpoint string `json:"endpoint"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil || input.Endpoint == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	result := repositories.DB.Where("endpoint = ? AND user_id = ?", input.Endpoint, userID).Delete(&models.PushSubscription{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to remove push subscription"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Push subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Push notifications disabled"})
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const maxAnswersPageSize = 50
+
+// POST /api/messages/:id/reply
+// Only replies sent with isPublic true appear on the public answers feed
+func ReplyToMessage(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Content  string `json:"content"`
+		IsPublic *bool  `json:"isPublic"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	input.Content = strings.TrimSpace(input.Content)
+	if input.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Content is required"})
+		return
+	}
+
+	message, ok := findOwnMessage(c, userID)
+	if !ok {
+		return
+	}
+
+	if input.IsPublic != nil && *input.IsPublic && !publishable(c, message) {
+		return
+	}
+
+	reply := models.Reply{
+		MessageID: message.ID,
+		UserID:    message.UserID,
+		Content:   input.Content,
+		IsPublic:  input.IsPublic != nil && *input.IsPublic,
+		CreatedAt: time.Now(),
+	}
+
+	err := repositories.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reply).Error; err != nil {
+			return err
+		}
+		if input.IsPublic == nil {
+			return nil
+		}
+		return setMessageVisibility(tx, &message, *input.IsPublic)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to save reply"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Reply saved successfully",
+		"data":    gin.H{"reply": reply, "isPublic": message.IsPublic},
+	})
+}
+
+// PATCH /api/messages/:id/visibility
+func SetMessageVisibility(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		IsPublic bool `json:"isPublic"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	message, ok := findOwnMessage(c, userID)
+	if !ok {
+		return
+	}
+
+	if input.IsPublic && !publishable(c, message) {
+		return
+	}
+
+	if err := setMessageVisibility(repositories.DB, &message, input.IsPublic); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update message"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Message visibility updated", "data": gin.H{"isPublic": message.IsPublic}})
+}
+
+// GET /api/user/:username/answers?page=1&limit=20
+func GetPublicAnswers(c *gin.Context) {
+	// gin needs one wildcard name per segment, so the username arrives as :id
+	username := c.Param("id")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > maxAnswersPageSize {
+		limit = maxAnswersPageSize
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("username = ?", username).First(&user).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return
+	}
+
+	var messages []models.Message
+	err := repositories.DB.
+		Preload("Replies", func(db *gorm.DB) *gorm.DB {
+			return db.Where("from_sender = ? AND is_public = ?", false, true).Order("created_at asc")
+		}).
+		Where("user_id = ? AND is_public = ? AND folder = ?", user.ID, true, models.FolderInbox).
+		Where("EXISTS (SELECT 1 FROM replies WHERE replies.message_id = messages.id AND replies.from_sender = ? AND replies.is_public = ?)", false, true).
+		Order("published_at desc").
+		Offset((page - 1) * limit).Limit(limit).
+		Find(&messages).Error
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch answers"})
+		return
+	}
+
+	answers := make([]gin.H, len(messages))
+	for i, m := range messages {
+		replies := make([]gin.H, len(m.Replies))
+		for j, r := range m.Replies {
+			replies[j] = gin.H{"content": r.Content, "createdAt": r.CreatedAt}
+		}
+		answers[i] = gin.H{
+			"id":          m.ID,
+			"question":    m.Content,
+			"askedAt":     m.CreatedAt,
+			"publishedAt": m.PublishedAt,
+			"answers":     replies,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Answers fetched successfully",
+		"data":    gin.H{"username": user.Username, "page": page, "limit": limit, "items": answers},
+	})
+}
+
+// findOwnMessage loads the :id message if it belongs to the user, writing the error response otherwise
+func findOwnMessage(c *gin.Context, userID interface{}) (models.Message, bool) {
+	var message models.Message
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Message not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch message"})
+		}
+		return message, false
+	}
+	return message, true
+}
+
+// publishable reports whether a message may go on the public feed, writing the error response otherwise
+func publishable(c *gin.Context, message models.Message) bool {
+	switch {
+	case message.Folder != models.FolderInbox:
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Quarantined messages cannot be published"})
+		return false
+	case message.IsEncrypted:
+		c.JSON(http.StatusConflict, gin.H{"success": false, "message": "Encrypted messages cannot be published"})
+		return false
+	}
+	return true
+}
+
+func setMessageVisibility(db *gorm.DB, message *models.Message, public bool) error {
+	if message.IsPublic == public {
+		return nil
+	}
+
+	var publishedAt *time.Time
+	if public {
+		now := time.Now()
+		publishedAt = &now
+	}
+
+	err := db.Model(message).Updates(map[string]interface{}{"is_public": public, "published_at": publishedAt}).Error
+	if err != nil {
+		return err
+	}
+	message.IsPublic, message.PublishedAt = public, publishedAt
+	return nil
+}
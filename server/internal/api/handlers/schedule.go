@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/schedule"
+	"gorm.io/gorm"
+)
+
+// GET /api/user/accept-schedule
+func GetAcceptSchedule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var sched models.AcceptSchedule
+	if err := repositories.DB.Where("user_id = ?", userID).First(&sched).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusOK, gin.H{"success": true, "message": "No schedule set", "data": nil})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch schedule"})
+		}
+		return
+	}
+
+	status, err := evaluateSchedule(sched, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Schedule fetched successfully", "data": gin.H{"schedule": sched, "status": status}})
+}
+
+// PUT /api/user/accept-schedule
+func SetAcceptSchedule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		Timezone    string                `json:"timezone"`
+		Windows     []models.AcceptWindow `json:"windows"`
+		MaxMessages *int                  `json:"maxMessages"`
+		CloseAt     *time.Time            `json:"closeAt"`
+		// Restart the maxMessages count from now
+		ResetCount bool `json:"resetCount"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	if input.Timezone == "" {
+		input.Timezone = "UTC"
+	}
+
+	sched := models.AcceptSchedule{UserID: uid, CountFrom: time.Now()}
+	err = repositories.DB.Where("user_id = ?", uid).First(&sched).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch schedule"})
+		return
+	}
+
+	sched.Timezone = input.Timezone
+	sched.Windows = input.Windows
+	sched.MaxMessages = input.MaxMessages
+	sched.CloseAt = input.CloseAt
+	if input.ResetCount {
+		sched.CountFrom = time.Now()
+	}
+
+	if err := schedule.Validate(sched); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": err.Error()})
+		return
+	}
+
+	if err := repositories.DB.Save(&sched).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to save schedule"})
+		return
+	}
+
+	status, _ := evaluateSchedule(sched, time.Now())
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Schedule saved", "data": gin.H{"schedule": sched, "status": status}})
+}
+
+// DELETE /api/user/accept-schedule
+func DeleteAcceptSchedule(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	if err := repositories.DB.Where("user_id = ?", userID).Delete(&models.AcceptSchedule{}).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete schedule"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Schedule removed"})
+}
+
+// scheduleStatus evaluates the user's schedule, if they have one
+func scheduleStatus(userID interface{}, now time.Time) (*schedule.Status, error) {
+	var sched models.AcceptSchedule
+	if err := repositories.DB.Where("user_id = ?", userID).First(&sched).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	status, err := evaluateSchedule(sched, now)
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func evaluateSchedule(sched models.AcceptSchedule, now time.Time) (schedule.Status, error) {
+	var received int64
+	if sched.MaxMessages != nil {
+		err := repositories.DB.Model(&models.Message{}).
+			Where("user_id = ? AND created_at >= ?", sched.UserID, sched.CountFrom).
+			Count(&received).Error
+		if err != nil {
+			return schedule.Status{}, err
+		}
+	}
+	return schedule.Evaluate(sched, now, received), nil
+}
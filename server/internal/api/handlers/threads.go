@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/e2ee"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
+	"gorm.io/gorm"
+)
+
+// decoyThreadKey signs the thread tokens handed to blocked senders
+var decoyThreadKey = []byte(config.TokenKey("thread decoy"))
+
+// GET /api/threads/:token
+func GetThread(c *gin.Context) {
+	if issuedAt, ok := decoyThread(c.Param("token")); ok {
+		c.JSON(http.StatusOK, gin.H{
+			"success": true,
+			"message": "Thread fetched successfully",
+			"data":    gin.H{"content": "", "isEncrypted": false, "createdAt": issuedAt, "replies": []gin.H{}},
+		})
+		return
+	}
+
+	message, ok := findThread(c)
+	if !ok {
+		return
+	}
+
+	entries := make([]gin.H, len(message.Replies))
+	for i, r := range message.Replies {
+		entries[i] = gin.H{"content": r.Content, "fromSender": r.FromSender, "isEncrypted": r.IsEncrypted, "createdAt": r.CreatedAt}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Thread fetched successfully",
+		"data": gin.H{
+			"content":     message.Content,
+			"isEncrypted": message.IsEncrypted,
+			"createdAt":   message.CreatedAt,
+			"replies":     entries,
+		},
+	})
+}
+
+// POST /api/threads/:token
+// Follow-ups reach the owner's inbox, so they pass the same checks as new
+// messages: the owner must be accepting messages, challenges apply, and
+// moderation or spam scoring can reject them or quarantine the thread.
+func FollowUpThread(c *gin.Context) {
+	var input struct {
+		Content      string `json:"content"`
+		Encrypted    bool   `json:"encrypted"`
+		Challenge    string `json:"challenge"`
+		Solution     string `json:"solution"`
+		CaptchaToken string `json:"captchaToken"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	input.Content = strings.TrimSpace(input.Content)
+	if input.Content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Content is required"})
+		return
+	}
+
+	// Follow-ups to a decoy thread are dropped like the blocked message was
+	if _, ok := decoyThread(c.Param("token")); ok {
+		c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Reply sent successfully"})
+		return
+	}
+
+	message, ok := findThread(c)
+	if !ok {
+		return
+	}
+
+	var owner models.User
+	if err := repositories.DB.Where("id = ?", message.UserID).First(&owner).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch thread"})
+		return
+	}
+
+	if !owner.IsAcceptingMessages {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "User is not accepting messages"})
+		return
+	}
+	status, err := scheduleStatus(owner.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to send reply"})
+		return
+	}
+	if status != nil && !status.Open {
+		c.JSON(http.StatusForbidden, gin.H{"success": false, "message": "User is not accepting messages right now", "data": gin.H{"nextOpenAt": status.NextOpenAt}})
+		return
+	}
+
+	// Follow-ups to end-to-end encrypted inboxes must be sealed to the owner's key too
+	if (owner.PublicKey != "") != input.Encrypted {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Follow-ups must match the inbox encryption setting", "data": gin.H{"publicKey": owner.PublicKey}})
+		return
+	}
+
+	var plaintext string
+	if input.Encrypted {
+		ciphertext, err := e2ee.ValidateSealedBox(input.Content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid ciphertext"})
+			return
+		}
+		input.Content = ciphertext
+	} else {
+		plaintext = input.Content
+	}
+
+	if challengeRequired(owner) {
+		if err := verifyChallenge(c, owner, input.Challenge, input.Solution, input.CaptchaToken); err != nil {
+			challengeFailed(c, err)
+			return
+		}
+	}
+
+	if isBlockedSubmission(owner, senderFingerprint(c, owner), plaintext) {
+		c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Reply sent successfully"})
+		return
+	}
+
+	verdict, _, err := screenMessage(owner, input.Content, input.Encrypted)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to send reply"})
+		return
+	}
+	if verdict.Verdict == moderation.Reject {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"success": false, "message": verdict.Reason})
+		return
+	}
+
+	reply := models.Reply{
+		MessageID:   message.ID,
+		UserID:      message.UserID,
+		Content:     input.Content,
+		FromSender:  true,
+		IsEncrypted: input.Encrypted,
+		CreatedAt:   time.Now(),
+	}
+	err = repositories.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&reply).Error; err != nil {
+			return err
+		}
+		// A quarantined follow-up takes its thread out of the inbox until the
+		// owner approves it, the sender is not told
+		if verdict.Verdict != moderation.Quarantine {
+			return nil
+		}
+		return tx.Model(&message).Updates(map[string]interface{}{"folder": models.FolderQuarantine, "moderation_reason": verdict.Reason}).Error
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to send reply"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"success": true, "message": "Reply sent successfully"})
+}
+
+// newDecoyThreadToken returns a token shaped like a thread token that no
+// message backs. Blocked senders who ask for a reply get one, and it opens an
+// empty thread, so the token does not tell them they were blocked.
+func newDecoyThreadToken() (string, error) {
+	var b [32]byte
+	binary.BigEndian.PutUint64(b[:8], uint64(time.Now().Unix()))
+	if _, err := rand.Read(b[8:16]); err != nil {
+		return "", err
+	}
+	copy(b[16:], decoyThreadMAC(b[:16]))
+	return base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+// decoyThread reports whether token is a decoy and when it was issued
+func decoyThread(token string) (time.Time, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) != 32 || !hmac.Equal(b[16:], decoyThreadMAC(b[:16])) {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(binary.BigEndian.Uint64(b[:8])), 0), true
+}
+
+func decoyThreadMAC(b []byte) []byte {
+	mac := hmac.New(sha256.New, decoyThreadKey)
+	mac.Write(b)
+	return mac.Sum(nil)[:16]
+}
+
+// ThreadRateKey counts follow-ups per thread. The token is hashed so the
+// limiter never holds the credential itself.
+func ThreadRateKey(c *gin.Context) string {
+	return "thread:" + utils.HashToken(c.Param("token"))
+}
+
+// findThread loads the message behind the :token thread, writing the error response otherwise
+func findThread(c *gin.Context) (models.Message, bool) {
+	var message models.Message
+	err := repositories.DB.
+		Preload("Replies", func(db *gorm.DB) *gorm.DB { return db.Order("created_at asc") }).
+		Where("thread_token_hash = ?", utils.HashToken(c.Param("token"))).
+		First(&message).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Thread not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch thread"})
+		}
+		return message, false
+	}
+	return message, true
+}
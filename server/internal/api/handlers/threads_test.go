@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
+)
+
+func threadRoutes(r *gin.Engine) {
+	r.GET("/threads/:token", GetThread)
+	r.POST("/threads/:token", FollowUpThread)
+}
+
+func TestDecoyThreadToken(t *testing.T) {
+	decoy, err := newDecoyThreadToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	real, _, _ := utils.NewSecretToken()
+	if len(decoy) != len(real) {
+		t.Errorf("decoy token has length %d, thread tokens %d", len(decoy), len(real))
+	}
+	if _, ok := decoyThread(decoy); !ok {
+		t.Error("decoy token not recognised")
+	}
+	if _, ok := decoyThread(real); ok {
+		t.Error("thread token taken for a decoy")
+	}
+	tampered := []byte(decoy)
+	tampered[len(tampered)-2] ^= 1
+	if _, ok := decoyThread(string(tampered)); ok {
+		t.Error("tampered decoy token accepted")
+	}
+}
+
+func TestDecoyThreadLooksEmpty(t *testing.T) {
+	decoy, _ := newDecoyThreadToken()
+
+	code, resp := serve(t, threadRoutes, http.MethodGet, "/threads/"+decoy, nil)
+	if code != http.StatusOK {
+		t.Fatalf("GET = %d %s", code, resp.Message)
+	}
+	var thread struct {
+		Content string            `json:"content"`
+		Replies []json.RawMessage `json:"replies"`
+	}
+	if err := json.Unmarshal(resp.Data, &thread); err != nil {
+		t.Fatal(err)
+	}
+	if thread.Content != "" || thread.Replies == nil || len(thread.Replies) != 0 {
+		t.Errorf("decoy thread = %s", resp.Data)
+	}
+
+	code, _ = serve(t, threadRoutes, http.MethodPost, "/threads/"+decoy, gin.H{"content": "still there?"})
+	if code != http.StatusCreated {
+		t.Errorf("follow-up = %d, want 201", code)
+	}
+}
+
+// createTestThread stores a message to owner that asked for replies and returns its token
+func createTestThread(t *testing.T, owner models.User) (models.Message, string) {
+	t.Helper()
+	token, hash, err := utils.NewSecretToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := models.Message{
+		UserID:          owner.ID,
+		Content:         "hello",
+		Folder:          models.FolderInbox,
+		ThreadTokenHash: &hash,
+		HasThread:       true,
+		CreatedAt:       time.Now(),
+	}
+	if err := repositories.DB.Create(&m).Error; err != nil {
+		t.Fatalf("create message: %v", err)
+	}
+	return m, token
+}
+
+func threadReplies(t *testing.T, m models.Message) []models.Reply {
+	t.Helper()
+	var replies []models.Reply
+	if err := repositories.DB.Where("message_id = ?", m.ID).Find(&replies).Error; err != nil {
+		t.Fatal(err)
+	}
+	return replies
+}
+
+func TestFollowUpThreadStoresReply(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	message, token := createTestThread(t, owner)
+
+	code, resp := serve(t, threadRoutes, http.MethodPost, "/threads/"+token, gin.H{"content": "  one more thing  "})
+	if code != http.StatusCreated {
+		t.Fatalf("follow-up = %d %s", code, resp.Message)
+	}
+	replies := threadReplies(t, message)
+	if len(replies) != 1 || replies[0].Content != "one more thing" || !replies[0].FromSender {
+		t.Fatalf("replies = %+v", replies)
+	}
+
+	code, resp = serve(t, threadRoutes, http.MethodGet, "/threads/"+token, nil)
+	if code != http.StatusOK {
+		t.Fatalf("GET = %d %s", code, resp.Message)
+	}
+	var thread struct {
+		Content string `json:"content"`
+		Replies []struct {
+			Content    string `json:"content"`
+			FromSender bool   `json:"fromSender"`
+		} `json:"replies"`
+	}
+	if err := json.Unmarshal(resp.Data, &thread); err != nil {
+		t.Fatal(err)
+	}
+	if thread.Content != "hello" || len(thread.Replies) != 1 || thread.Replies[0].Content != "one more thing" {
+		t.Errorf("thread = %s", resp.Data)
+	}
+}
+
+func TestFollowUpThreadChecks(t *testing.T) {
+	requireDB(t)
+
+	tests := []struct {
+		name    string
+		owner   func(u *models.User)
+		body    gin.H
+		want    int
+		replies int
+	}{
+		{"closed inbox", func(u *models.User) { u.IsAcceptingMessages = false }, gin.H{"content": "hi"}, http.StatusForbidden, 0},
+		{"empty content", nil, gin.H{"content": "   "}, http.StatusBadRequest, 0},
+		{"ciphertext to a plaintext inbox", nil, gin.H{"content": "hi", "encrypted": true}, http.StatusBadRequest, 0},
+		{"plaintext to an encrypted inbox", func(u *models.User) { u.PublicKey = "AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=" }, gin.H{"content": "hi"}, http.StatusBadRequest, 0},
+		{"challenge required", func(u *models.User) { u.RequireChallenge = true }, gin.H{"content": "hi"}, http.StatusForbidden, 0},
+		{"rejected link", func(u *models.User) { u.BlockLinks = true; u.ModerationLevel = "high" }, gin.H{"content": "see https://example.com"}, http.StatusUnprocessableEntity, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []func(u *models.User)
+			if tt.owner != nil {
+				opts = append(opts, tt.owner)
+			}
+			owner := createTestUser(t, opts...)
+			message, token := createTestThread(t, owner)
+
+			code, resp := serve(t, threadRoutes, http.MethodPost, "/threads/"+token, tt.body)
+			if code != tt.want {
+				t.Errorf("follow-up = %d %s, want %d", code, resp.Message, tt.want)
+			}
+			if got := len(threadReplies(t, message)); got != tt.replies {
+				t.Errorf("%d replies stored, want %d", got, tt.replies)
+			}
+		})
+	}
+}
+
+func TestFollowUpThreadQuarantinesThread(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t, func(u *models.User) { u.BlockLinks = true })
+	message, token := createTestThread(t, owner)
+
+	code, resp := serve(t, threadRoutes, http.MethodPost, "/threads/"+token, gin.H{"content": "see https://example.com"})
+	if code != http.StatusCreated {
+		t.Fatalf("follow-up = %d %s", code, resp.Message)
+	}
+	var got models.Message
+	repositories.DB.Where("id = ?", message.ID).First(&got)
+	if got.Folder != models.FolderQuarantine || got.ModerationReason == "" {
+		t.Errorf("thread folder = %q, reason %q", got.Folder, got.ModerationReason)
+	}
+	if len(threadReplies(t, message)) != 1 {
+		t.Error("quarantined follow-up was not kept for review")
+	}
+}
+
+func TestFollowUpThreadDropsBlockedSender(t *testing.T) {
+	requireDB(t)
+	owner := createTestUser(t)
+	message, token := createTestThread(t, owner)
+	blocked := models.BlockedSender{
+		UserID:      owner.ID,
+		Fingerprint: utils.SenderFingerprint(config.Envs.FingerprintSalt, owner.ID.String(), "203.0.113.7", ""),
+	}
+	if err := repositories.DB.Create(&blocked).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	code, _ := serve(t, threadRoutes, http.MethodPost, "/threads/"+token, gin.H{"content": "again"})
+	if code != http.StatusCreated {
+		t.Errorf("follow-up = %d, want the usual 201", code)
+	}
+	if got := len(threadReplies(t, message)); got != 0 {
+		t.Errorf("%d replies stored from a blocked sender", got)
+	}
+}
@@ -2,14 +2,22 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
 	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
 	"gorm.io/gorm"
 )
 
+// parseUserID converts the userID set by AuthMiddleware into a UUID
+func parseUserID(userID interface{}) (uuid.UUID, error) {
+	id, _ := userID.(string)
+	return uuid.Parse(id)
+}
+
 // GET /api/user/info
 func GetUserInfo(c *gin.Context) {
 	userID, exists := c.Get("userID")
@@ -37,6 +45,8 @@ func GetUserInfo(c *gin.Context) {
 			"email":               user.Email,
 			"isVerified":          user.IsVerified,
 			"isAcceptingMessages": user.IsAcceptingMessages,
+			"droppedMessages":     user.DroppedMessages,
+			"publicKey":           user.PublicKey,
 			"createdAt":           user.CreatedAt,
 			"updatedAt":           user.UpdatedAt,
 		},
@@ -127,5 +137,99 @@ func GetAcceptMessagesStatus(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"success": true, "message": "User preference fetched successfully", "data": gin.H{"isAcceptingMessages": user.IsAcceptingMessages}})
+	status, err := scheduleStatus(user.ID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch user preference"})
+		return
+	}
+
+	// isAcceptingMessages is the manual toggle, acceptingNow also applies the schedule
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "User preference fetched successfully",
+		"data": gin.H{
+			"isAcceptingMessages": user.IsAcceptingMessages,
+			"acceptingNow":        user.IsAcceptingMessages && (status == nil || status.Open),
+			"schedule":            status,
+		},
+	})
+}
+
+// GET /api/user/moderation
+func GetModerationSettings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("id = ?", userID).First(&user).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch moderation settings"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "Moderation settings fetched successfully",
+		"data": gin.H{
+			"moderationLevel":  user.ModerationLevel,
+			"blockLinks":       user.BlockLinks,
+			"requireChallenge": user.RequireChallenge,
+			// Content filters cannot run on end-to-end encrypted inboxes
+			"moderationActive": user.PublicKey == "",
+		},
+	})
+}
+
+// PATCH /api/user/moderation
+func UpdateModerationSettings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		ModerationLevel  *string `json:"moderationLevel"`
+		BlockLinks       *bool   `json:"blockLinks"`
+		RequireChallenge *bool   `json:"requireChallenge"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	updates := map[string]interface{}{}
+	if input.ModerationLevel != nil {
+		level, ok := moderation.ParseSensitivity(*input.ModerationLevel)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "moderationLevel must be one of off, low, medium, high"})
+			return
+		}
+		updates["moderation_level"] = string(level)
+	}
+	if input.BlockLinks != nil {
+		updates["block_links"] = *input.BlockLinks
+	}
+	if input.RequireChallenge != nil {
+		updates["require_challenge"] = *input.RequireChallenge
+	}
+	if len(updates) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Nothing to update"})
+		return
+	}
+
+	result := repositories.DB.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update moderation settings"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "User not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Moderation settings updated"})
 }
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+	"github.com/rohits-web03/SilentEcho/server/internal/worker"
+	"gorm.io/gorm"
+)
+
+const (
+	maxWebhooksPerUser   = 10
+	maxWebhookDeliveries = 100
+)
+
+type WebhookHandler struct {
+	rmq *queue.RabbitMQ
+}
+
+func NewWebhookHandler(rmq *queue.RabbitMQ) *WebhookHandler {
+	return &WebhookHandler{rmq: rmq}
+}
+
+// GET /api/webhooks
+func GetWebhooks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var hooks []models.Webhook
+	if err := repositories.DB.Order("created_at desc").Where("user_id = ?", userID).Find(&hooks).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch webhooks"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Webhooks fetched successfully", "data": hooks})
+}
+
+// POST /api/webhooks
+func CreateWebhook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		URL    string   `json:"url"`
+		Events []string `json:"events"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	uid, err := parseUserID(userID)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	url := strings.TrimSpace(input.URL)
+	if msg := validateWebhook(url, input.Events); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	var count int64
+	repositories.DB.Model(&models.Webhook{}).Where("user_id = ?", uid).Count(&count)
+	if count >= maxWebhooksPerUser {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Webhook limit reached"})
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create webhook"})
+		return
+	}
+
+	hook := models.Webhook{
+		UserID:   uid,
+		URL:      url,
+		Secret:   secret,
+		Events:   dedupe(input.Events),
+		IsActive: true,
+	}
+	if err := repositories.DB.Create(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to create webhook"})
+		return
+	}
+
+	// The secret is only ever shown here and on rotation
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"message": "Webhook created successfully",
+		"data":    gin.H{"webhook": hook, "secret": secret},
+	})
+}
+
+// PATCH /api/webhooks/:id
+func UpdateWebhook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	var input struct {
+		URL      *string  `json:"url"`
+		Events   []string `json:"events"`
+		IsActive *bool    `json:"isActive"`
+	}
+
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": "Invalid input"})
+		return
+	}
+
+	hook, ok := findWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	if input.URL != nil {
+		hook.URL = strings.TrimSpace(*input.URL)
+	}
+	if input.Events != nil {
+		hook.Events = dedupe(input.Events)
+	}
+	if input.IsActive != nil {
+		hook.IsActive = *input.IsActive
+	}
+	if msg := validateWebhook(hook.URL, hook.Events); msg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"success": false, "message": msg})
+		return
+	}
+
+	if err := repositories.DB.Save(&hook).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to update webhook"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Webhook updated successfully", "data": hook})
+}
+
+// DELETE /api/webhooks/:id
+func DeleteWebhook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	// Its delivery log goes with it
+	result := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).Delete(&models.Webhook{})
+	if result.Error != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to delete webhook"})
+		return
+	}
+	if result.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Webhook not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Webhook deleted successfully"})
+}
+
+// POST /api/webhooks/:id/rotate-secret
+func RotateWebhookSecret(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	hook, ok := findWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to rotate secret"})
+		return
+	}
+
+	// Retries still pending are signed with the new secret
+	if err := repositories.DB.Model(&hook).Update("secret", secret).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to rotate secret"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Secret rotated successfully", "data": gin.H{"secret": secret}})
+}
+
+// GET /api/webhooks/:id/deliveries
+func GetWebhookDeliveries(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	hook, ok := findWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	query := repositories.DB.Order("created_at desc").Where("webhook_id = ?", hook.ID).Limit(maxWebhookDeliveries)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var deliveries []models.WebhookDelivery
+	if err := query.Find(&deliveries).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch deliveries"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "message": "Deliveries fetched successfully", "data": deliveries})
+}
+
+// POST /api/webhooks/:id/deliveries/:deliveryId/redeliver
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"success": false, "message": "Unauthorized"})
+		return
+	}
+
+	hook, ok := findWebhook(c, userID)
+	if !ok {
+		return
+	}
+
+	var original models.WebhookDelivery
+	if err := repositories.DB.Where("id = ? AND webhook_id = ?", c.Param("deliveryId"), hook.ID).First(&original).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Delivery not found"})
+		return
+	}
+
+	// A redelivery is logged on its own, receivers dedupe on the event ID
+	now := time.Now()
+	delivery := models.WebhookDelivery{
+		WebhookID:     hook.ID,
+		EventID:       original.EventID,
+		EventType:     original.EventType,
+		Payload:       original.Payload,
+		Status:        models.DeliveryPending,
+		NextAttemptAt: &now,
+	}
+	if err := repositories.DB.Create(&delivery).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to redeliver"})
+		return
+	}
+	if err := worker.EnqueueDelivery(h.rmq, delivery.ID); err != nil {
+		log.Printf("Failed to enqueue delivery %s: %v\n", delivery.ID, err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"success": true, "message": "Redelivery queued", "data": delivery})
+}
+
+// findWebhook loads the :id webhook owned by the user, writing the error response if missing
+func findWebhook(c *gin.Context, userID interface{}) (models.Webhook, bool) {
+	var hook models.Webhook
+	if err := repositories.DB.Where("id = ? AND user_id = ?", c.Param("id"), userID).First(&hook).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"success": false, "message": "Webhook not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"success": false, "message": "Failed to fetch webhook"})
+		}
+		return hook, false
+	}
+	return hook, true
+}
+
+func validateWebhook(url string, eventTypes []string) string {
+	if err := webhook.ValidateURL(url, config.Envs.AllowPrivateWebhooks); err != nil {
+		return "Invalid webhook URL: " + err.Error()
+	}
+	if len(eventTypes) == 0 {
+		return "Subscribe to at least one event"
+	}
+	for _, t := range eventTypes {
+		if !isWebhookType(t) {
+			return "Unknown event type: " + t
+		}
+	}
+	return ""
+}
+
+func isWebhookType(t string) bool {
+	for _, known := range events.WebhookTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
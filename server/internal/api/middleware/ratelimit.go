@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit allows each client IP at most limit requests per window. Counts
+// live in memory, so with several instances the effective limit is per instance.
+func RateLimit(limit int, window time.Duration) gin.HandlerFunc {
+	return RateLimitBy(limit, window, func(c *gin.Context) string { return c.ClientIP() })
+}
+
+// RateLimitBy is RateLimit with requests counted per key instead of per IP
+func RateLimitBy(limit int, window time.Duration, key func(c *gin.Context) string) gin.HandlerFunc {
+	type bucket struct {
+		count   int
+		resetAt time.Time
+	}
+
+	var (
+		mu        sync.Mutex
+		buckets   = make(map[string]*bucket)
+		lastSweep time.Time
+	)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		k := key(c)
+
+		mu.Lock()
+		// Drop finished windows now and then so the map does not grow forever
+		if now.Sub(lastSweep) > window {
+			for key, b := range buckets {
+				if now.After(b.resetAt) {
+					delete(buckets, key)
+				}
+			}
+			lastSweep = now
+		}
+
+		b, ok := buckets[k]
+		if !ok || now.After(b.resetAt) {
+			b = &bucket{resetAt: now.Add(window)}
+			buckets[k] = b
+		}
+		b.count++
+		allowed := b.count <= limit
+		retryAfter := b.resetAt.Sub(now)
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", formatSeconds(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"success": false, "message": "Too many requests, try again later"})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func formatSeconds(d time.Duration) string {
+	seconds := int(d.Seconds() + 0.999)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -1,18 +1,26 @@
 package api
 
 import (
+	"log"
 	"net/http"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/rohits-web03/SilentEcho/server/internal/api/handlers"
 	"github.com/rohits-web03/SilentEcho/server/internal/api/middleware"
+	"github.com/rohits-web03/SilentEcho/server/internal/blobstore"
 	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
 	"github.com/rohits-web03/SilentEcho/server/internal/queue"
 )
 
-func SetupRouter(rmq *queue.RabbitMQ) *gin.Engine {
+func SetupRouter(rmq *queue.RabbitMQ, hub *events.Hub, store blobstore.Store) *gin.Engine {
 	router := gin.Default()
+	// Without this gin believes X-Forwarded-For from anyone, and senders
+	// could pick the IP their fingerprint is made from
+	if err := router.SetTrustedProxies(config.Envs.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	router.Use(cors.New(config.Envs.CorsConfig))
 	// Routes
 	{
@@ -32,31 +40,138 @@ func SetupRouter(rmq *queue.RabbitMQ) *gin.Engine {
 		// Messages
 		{
 			messageRouter := apiRouter.Group("/messages")
-			messageRouter.POST("/", handlers.SendMessage)
+			messageHandler := handlers.NewMessageHandler(rmq, hub)
+			messageRouter.POST("/", messageHandler.SendMessage)
+			messageRouter.GET("/challenge", handlers.GetChallenge)
 			messageRouter.Use(middleware.AuthMiddleware())
 			messageRouter.GET("/", handlers.GetMessages)
+			messageRouter.GET("/stream", messageHandler.StreamMessages)
+			messageRouter.GET("/ws", messageHandler.StreamMessagesWS)
+			messageRouter.POST("/encrypt", handlers.EncryptExistingMessages)
 			messageRouter.DELETE("/:id", handlers.DeleteMessage)
+			messageRouter.POST("/:id/approve", handlers.ApproveMessage)
+			messageRouter.POST("/:id/block-sender", handlers.BlockMessageSender)
+			messageRouter.POST("/:id/reply", handlers.ReplyToMessage)
+			messageRouter.PATCH("/:id/visibility", handlers.SetMessageVisibility)
+		}
+
+		// Anonymous threads, the secret token is the only credential
+		{
+			threadRouter := apiRouter.Group("/threads")
+			threadRouter.GET("/:token", handlers.GetThread)
+			threadRouter.POST("/:token", middleware.RateLimitBy(config.Envs.ThreadFollowUpLimit, config.Envs.ThreadFollowUpWindow, handlers.ThreadRateKey), handlers.FollowUpThread)
+		}
+
+		// Prompts
+		{
+			promptRouter := apiRouter.Group("/prompts")
+			promptRouter.GET("/public/:slug", handlers.GetPublicPrompt)
+			promptRouter.Use(middleware.AuthMiddleware())
+			promptRouter.GET("/", handlers.GetPrompts)
+			promptRouter.POST("/", handlers.CreatePrompt)
+			promptRouter.PATCH("/:id", handlers.UpdatePrompt)
+			promptRouter.DELETE("/:id", handlers.DeletePrompt)
 		}
 
 		// Notes
 		{
 			noteRouter := apiRouter.Group("/notes")
-			noteRouter.GET("/:slug", handlers.GetNote)
+			noteHandler := handlers.NewNoteHandler(rmq)
+			attachmentHandler := handlers.NewAttachmentHandler(store)
+			noteRouter.GET("/:slug", noteHandler.GetNote)
+			noteRouter.POST("/:slug/unlock", middleware.RateLimit(config.Envs.NoteUnlockLimit, config.Envs.NoteUnlockWindow), noteHandler.UnlockNote)
+			noteRouter.POST("/anonymous", middleware.RateLimit(config.Envs.AnonymousNoteLimit, config.Envs.AnonymousNoteWindow), handlers.CreateAnonymousNote)
+			noteRouter.GET("/:slug/manage", handlers.InspectAnonymousNote)
+			noteRouter.DELETE("/:slug/manage", handlers.DeleteAnonymousNote)
+			noteRouter.GET("/:slug/attachments/:attachmentId", attachmentHandler.DownloadAttachment)
 			noteRouter.Use(middleware.AuthMiddleware())
 			noteRouter.POST("/", handlers.CreateNote)
 			noteRouter.GET("/user/:userId", handlers.GetUserNotes)
+			noteRouter.GET("/folders", handlers.GetNoteFolders)
+			noteRouter.POST("/folders", handlers.CreateNoteFolder)
+			noteRouter.PATCH("/folders/:id", handlers.UpdateNoteFolder)
+			noteRouter.DELETE("/folders/:id", handlers.DeleteNoteFolder)
+			noteRouter.GET("/labels", handlers.GetNoteLabels)
+			noteRouter.GET("/shared", handlers.GetSharedNotes)
+			noteRouter.GET("/shared/:slug", noteHandler.GetSharedNote)
+			noteRouter.GET("/:slug/recipients", handlers.GetNoteRecipients)
+			noteRouter.POST("/:slug/recipients", handlers.AddNoteRecipients)
+			noteRouter.DELETE("/:slug/recipients/:username", handlers.RevokeNoteRecipient)
+			noteRouter.PATCH("/:slug", handlers.UpdateNote)
+			noteRouter.GET("/:slug/views", handlers.GetNoteViews)
+			noteRouter.GET("/:slug/attachments", handlers.GetAttachments)
+			noteRouter.POST("/:slug/attachments", handlers.CreateAttachment)
+			noteRouter.PUT("/:slug/attachments/:attachmentId/chunks/:index", attachmentHandler.UploadChunk)
+			noteRouter.POST("/:slug/attachments/:attachmentId/complete", handlers.CompleteAttachment)
+			noteRouter.DELETE("/:slug/attachments/:attachmentId", attachmentHandler.DeleteAttachment)
+			noteRouter.DELETE("/:slug", handlers.DeleteNote)
+		}
+
+		// Webhooks
+		{
+			webhookRouter := apiRouter.Group("/webhooks")
+			webhookHandler := handlers.NewWebhookHandler(rmq)
+			webhookRouter.Use(middleware.AuthMiddleware())
+			webhookRouter.GET("/", handlers.GetWebhooks)
+			webhookRouter.POST("/", handlers.CreateWebhook)
+			webhookRouter.PATCH("/:id", handlers.UpdateWebhook)
+			webhookRouter.DELETE("/:id", handlers.DeleteWebhook)
+			webhookRouter.POST("/:id/rotate-secret", handlers.RotateWebhookSecret)
+			webhookRouter.GET("/:id/deliveries", handlers.GetWebhookDeliveries)
+			webhookRouter.POST("/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+		}
+
+		// Chat integrations
+		{
+			integrationRouter := apiRouter.Group("/integrations")
+			integrationRouter.Use(middleware.AuthMiddleware())
+			integrationRouter.GET("/", handlers.GetIntegrations)
+			integrationRouter.POST("/", handlers.CreateIntegration)
+			integrationRouter.PATCH("/:id", handlers.UpdateIntegration)
+			integrationRouter.DELETE("/:id", handlers.DeleteIntegration)
+			integrationRouter.POST("/:id/test", handlers.TestIntegration)
+		}
+
+		// Web Push
+		{
+			pushRouter := apiRouter.Group("/push")
+			pushRouter.GET("/vapid-public-key", handlers.GetVAPIDPublicKey)
+			pushRouter.Use(middleware.AuthMiddleware())
+			pushRouter.GET("/subscriptions", handlers.GetPushSubscriptions)
+			pushRouter.POST("/subscriptions", handlers.SubscribePush)
+			pushRouter.DELETE("/subscriptions", handlers.UnsubscribePush)
 		}
 
 		// Users
 		{
 			userRouter := apiRouter.Group("/user")
 			userRouter.GET("/check-username", handlers.CheckUsername)
+			userRouter.GET("/:id/answers", handlers.GetPublicAnswers)
+			userRouter.GET("/:id/public-key", handlers.GetPublicKey)
 			userRouter.Use(middleware.AuthMiddleware())
 			userRouter.GET("/info", handlers.GetUserInfo)
+			userRouter.GET("/moderation", handlers.GetModerationSettings)
+			userRouter.PATCH("/moderation", handlers.UpdateModerationSettings)
+			userRouter.GET("/blocked-words", handlers.GetBlockedWords)
+			userRouter.POST("/blocked-words", handlers.AddBlockedWord)
+			userRouter.DELETE("/blocked-words/:id", handlers.DeleteBlockedWord)
+			userRouter.GET("/blocked-senders", handlers.GetBlockedSenders)
+			userRouter.DELETE("/blocked-senders/:id", handlers.DeleteBlockedSender)
+			userRouter.GET("/notifications", handlers.GetNotificationSettings)
+			userRouter.PATCH("/notifications", handlers.UpdateNotificationSettings)
+			userRouter.GET("/accept-schedule", handlers.GetAcceptSchedule)
+			userRouter.PUT("/accept-schedule", handlers.SetAcceptSchedule)
+			userRouter.DELETE("/accept-schedule", handlers.DeleteAcceptSchedule)
+			userRouter.PUT("/public-key", handlers.SetPublicKey)
+			userRouter.DELETE("/public-key", handlers.DeletePublicKey)
 			userRouter.GET("/:id/accept-messages", handlers.GetAcceptMessagesStatus)
 			userRouter.PATCH("/:id/accept-messages", handlers.AcceptMessages)
 		}
 
+		// Email unsubscribe links work without logging in
+		apiRouter.GET("/notifications/unsubscribe", handlers.Unsubscribe)
+		apiRouter.POST("/notifications/unsubscribe", handlers.Unsubscribe)
+
 		// Welcome
 		apiRouter.GET("/", handlers.Welcome)
 
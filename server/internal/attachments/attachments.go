@@ -0,0 +1,64 @@
+package attachments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/blobstore"
+)
+
+var ErrInvalidToken = errors.New("invalid or expired download token")
+
+// ChunkKey is where a chunk lives in the blob store
+func ChunkKey(attachmentID uuid.UUID, index int) string {
+	return fmt.Sprintf("attachments/%s/%d", attachmentID, index)
+}
+
+// DownloadToken lets whoever just read a note fetch its attachments until
+// expires, even once the note itself has used up its views
+func DownloadToken(secret string, attachmentID uuid.UUID, expires time.Time) string {
+	exp := strconv.FormatInt(expires.Unix(), 10)
+	return exp + "." + base64.RawURLEncoding.EncodeToString(sign(secret, attachmentID, exp))
+}
+
+// VerifyDownloadToken checks a token was issued for the attachment and is still valid
+func VerifyDownloadToken(secret, token string, attachmentID uuid.UUID, now time.Time) error {
+	exp, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, sign(secret, attachmentID, exp)) {
+		return ErrInvalidToken
+	}
+	unix, err := strconv.ParseInt(exp, 10, 64)
+	if err != nil || now.Unix() > unix {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+// DeleteChunks removes every chunk blob of an attachment
+func DeleteChunks(ctx context.Context, store blobstore.Store, attachmentID uuid.UUID, chunkCount int) error {
+	for i := 0; i < chunkCount; i++ {
+		if err := store.Delete(ctx, ChunkKey(attachmentID, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sign(secret string, attachmentID uuid.UUID, exp string) []byte {
+	mac := hmac.New(sha256.New, []byte("attachment:"+secret))
+	mac.Write(attachmentID[:])
+	mac.Write([]byte(exp))
+	return mac.Sum(nil)
+}
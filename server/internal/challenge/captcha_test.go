@@ -0,0 +1,21 @@
+package challenge
+
+import "testing"
+
+func TestNewVerifier(t *testing.T) {
+	if v, err := NewVerifier("", "", true); v != nil || err != nil {
+		t.Errorf("no provider: got %v, %v", v, err)
+	}
+	if _, err := NewVerifier("fake", "", false); err != nil {
+		t.Errorf("fake outside release mode: %v", err)
+	}
+	if v, err := NewVerifier("fake", "", true); v != nil || err == nil {
+		t.Errorf("fake in release mode: got %v, %v", v, err)
+	}
+	if _, err := NewVerifier("turnstile", "secret", true); err != nil {
+		t.Errorf("turnstile: %v", err)
+	}
+	if _, err := NewVerifier("nope", "", false); err == nil {
+		t.Error("unknown provider accepted")
+	}
+}
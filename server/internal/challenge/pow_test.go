@@ -0,0 +1,102 @@
+package challenge
+
+import (
+	"crypto/sha256"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// solve brute forces a solution the way clients do
+func solve(t *testing.T, c Challenge) string {
+	t.Helper()
+	for n := 0; n < 1<<24; n++ {
+		solution := strconv.Itoa(n)
+		sum := sha256.Sum256([]byte(c.Token + ":" + solution))
+		if leadingZeroBits(sum[:]) >= c.Difficulty {
+			return solution
+		}
+	}
+	t.Fatal("no solution found")
+	return ""
+}
+
+func TestIssueVerify(t *testing.T) {
+	issuer := NewIssuer("secret", 8, time.Minute)
+	c, err := issuer.Issue("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := solve(t, c)
+
+	id, expiresAt, err := issuer.Verify(c.Token, solution, "alice")
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if id == "" || !expiresAt.Equal(time.Unix(c.ExpiresAt.Unix(), 0)) {
+		t.Errorf("Verify returned id %q, expiry %v", id, expiresAt)
+	}
+
+	// Each issued challenge has its own ID
+	other, _ := issuer.Issue("alice")
+	otherID, _, err := issuer.Verify(other.Token, solve(t, other), "alice")
+	if err != nil || otherID == id {
+		t.Errorf("second challenge: id %q, err %v", otherID, err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	issuer := NewIssuer("secret", 8, time.Minute)
+	c, err := issuer.Issue("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	solution := solve(t, c)
+
+	// A solution too weak for a harder challenge
+	hard := NewIssuer("secret", 64, time.Minute)
+	hc, _ := hard.Issue("alice")
+
+	expired, _ := NewIssuer("secret", 0, -time.Minute).Issue("alice")
+
+	tests := []struct {
+		name     string
+		issuer   *Issuer
+		token    string
+		solution string
+		subject  string
+		want     error
+	}{
+		{"other subject", issuer, c.Token, solution, "bob", ErrInvalid},
+		{"other secret", NewIssuer("other", 8, time.Minute), c.Token, solution, "alice", ErrInvalid},
+		{"tampered token", issuer, "x" + c.Token, solution, "alice", ErrInvalid},
+		{"no signature", issuer, "abc", solution, "alice", ErrInvalid},
+		{"empty solution", issuer, c.Token, "", "alice", ErrInvalid},
+		{"expired", issuer, expired.Token, "0", "alice", ErrExpired},
+		{"insufficient work", hard, hc.Token, "0", "alice", ErrInsufficientWork},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, err := tt.issuer.Verify(tt.token, tt.solution, tt.subject); err != tt.want {
+				t.Errorf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want int
+	}{
+		{[]byte{0x80}, 0},
+		{[]byte{0x01}, 7},
+		{[]byte{0x00, 0x40}, 9},
+		{[]byte{0x00, 0x00}, 16},
+	}
+	for _, tt := range tests {
+		if got := leadingZeroBits(tt.in); got != tt.want {
+			t.Errorf("leadingZeroBits(%x) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
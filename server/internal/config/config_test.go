@@ -0,0 +1,27 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetEnvList(t *testing.T) {
+	t.Setenv("TEST_LIST", " 10.0.0.0/8, ,192.168.1.1,")
+	if got, want := getEnvList("TEST_LIST"), []string{"10.0.0.0/8", "192.168.1.1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("getEnvList = %q, want %q", got, want)
+	}
+	if got := getEnvList("TEST_LIST_UNSET"); got != nil {
+		t.Errorf("unset list = %q, want nil", got)
+	}
+}
+
+func TestFingerprintSalt(t *testing.T) {
+	t.Setenv("FINGERPRINT_SALT", "pepper")
+	if got := fingerprintSalt(true); got != "pepper" {
+		t.Errorf("fingerprintSalt = %q", got)
+	}
+	t.Setenv("FINGERPRINT_SALT", "")
+	if got := fingerprintSalt(false); got != defaultFingerprintSalt {
+		t.Errorf("development salt = %q", got)
+	}
+}
@@ -0,0 +1,45 @@
+package config
+
+import (
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"log"
+	"sync"
+)
+
+var (
+	tokenSecretOnce sync.Once
+	tokenSecret     []byte
+)
+
+// TokenKey derives the HMAC key for one kind of signed token, e.g.
+// "challenge", from TOKEN_SECRET. Kinds get independent keys, and none of
+// them is the JWT secret, so a token of one kind never verifies as another.
+func TokenKey(purpose string) string {
+	key, err := hkdf.Key(sha256.New, loadTokenSecret(), nil, "silentecho "+purpose, 32)
+	if err != nil {
+		log.Fatalf("Failed to derive %s key: %v", purpose, err)
+	}
+	return string(key)
+}
+
+// loadTokenSecret returns TOKEN_SECRET. Outside release mode a missing secret
+// is replaced by a random one, so tokens do not survive a restart.
+func loadTokenSecret() []byte {
+	tokenSecretOnce.Do(func() {
+		if Envs.TokenSecret != "" {
+			tokenSecret = []byte(Envs.TokenSecret)
+			return
+		}
+		if Envs.GINMode == "release" {
+			log.Fatal("TOKEN_SECRET must be set in release mode")
+		}
+		log.Println("TOKEN_SECRET is not set, using a random secret for this process")
+		tokenSecret = make([]byte, 32)
+		if _, err := rand.Read(tokenSecret); err != nil {
+			log.Fatalf("Failed to generate token secret: %v", err)
+		}
+	})
+	return tokenSecret
+}
@@ -0,0 +1,59 @@
+package e2ee
+
+import (
+	"encoding/base64"
+	"errors"
+
+	"golang.org/x/crypto/nacl/box"
+)
+
+// Algorithm identifies the scheme clients must use: an X25519 public key
+// and libsodium compatible sealed boxes (crypto_box_seal).
+const Algorithm = "x25519-sealedbox"
+
+// MaxPlaintextSize bounds encrypted messages like plaintext ones
+const MaxPlaintextSize = 16 * 1024
+
+var (
+	ErrInvalidPublicKey  = errors.New("public key must be a base64 encoded 32 byte X25519 key")
+	ErrInvalidCiphertext = errors.New("ciphertext must be a base64 encoded sealed box")
+	ErrCiphertextTooLong = errors.New("ciphertext is too long")
+)
+
+// ParsePublicKey validates a base64 X25519 public key and returns it in
+// canonical standard base64.
+func ParsePublicKey(encoded string) (string, error) {
+	raw, err := decode(encoded)
+	if err != nil || len(raw) != 32 {
+		return "", ErrInvalidPublicKey
+	}
+
+	var zero [32]byte
+	if string(raw) == string(zero[:]) {
+		return "", ErrInvalidPublicKey
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// ValidateSealedBox checks that ciphertext is structurally a sealed box.
+// The server cannot decrypt it, so this is all the checking it can do.
+func ValidateSealedBox(encoded string) (string, error) {
+	raw, err := decode(encoded)
+	if err != nil || len(raw) <= box.AnonymousOverhead {
+		return "", ErrInvalidCiphertext
+	}
+	if len(raw) > MaxPlaintextSize+box.AnonymousOverhead {
+		return "", ErrCiphertextTooLong
+	}
+	return base64.StdEncoding.EncodeToString(raw), nil
+}
+
+// decode accepts both standard and URL-safe base64, padded or not
+func decode(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if raw, err := enc.DecodeString(s); err == nil {
+			return raw, nil
+		}
+	}
+	return nil, errors.New("invalid base64")
+}
@@ -0,0 +1,148 @@
+package envelope
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SealedPrefix marks values written by Seal, anything else is legacy plaintext
+const SealedPrefix = "env1:"
+
+var ErrUnknownKey = errors.New("unknown master key")
+
+// Keyring holds the master keys that wrap per-user data keys. Only the
+// current key wraps new data keys, older ones are kept to unwrap until
+// every data key has been rotated.
+type Keyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+// ParseKeyring reads keys in the form "id:base64key,id:base64key". The
+// current key is currentID, or the last one listed when it is empty. An
+// empty spec gives a disabled keyring.
+func ParseKeyring(spec, currentID string) (*Keyring, error) {
+	k := &Keyring{keys: map[string][]byte{}}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("master key entry %q must look like id:base64key", entry)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("master key %q must be 32 bytes of base64", id)
+		}
+		k.keys[id] = key
+		k.current = id
+	}
+
+	if currentID != "" {
+		if _, ok := k.keys[currentID]; !ok {
+			return nil, fmt.Errorf("current master key %q is not configured", currentID)
+		}
+		k.current = currentID
+	}
+	return k, nil
+}
+
+// Enabled reports whether any master key is configured.
+func (k *Keyring) Enabled() bool {
+	return k != nil && k.current != ""
+}
+
+// CurrentID is the ID of the key that wraps new data keys.
+func (k *Keyring) CurrentID() string {
+	return k.current
+}
+
+// NewDataKey generates a random data key and wraps it with the current master key.
+func (k *Keyring) NewDataKey() (dek []byte, wrapped string, err error) {
+	dek = make([]byte, 32)
+	if _, err = rand.Read(dek); err != nil {
+		return nil, "", err
+	}
+	wrapped, err = k.Wrap(dek)
+	return dek, wrapped, err
+}
+
+// Wrap encrypts a data key with the current master key.
+func (k *Keyring) Wrap(dek []byte) (string, error) {
+	return seal(k.keys[k.current], dek, []byte(k.current))
+}
+
+// Unwrap decrypts a data key wrapped by the master key kid.
+func (k *Keyring) Unwrap(wrapped, kid string) ([]byte, error) {
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownKey, kid)
+	}
+	return open(key, wrapped, []byte(kid))
+}
+
+// Seal encrypts plaintext with a data key, binding it to aad.
+func Seal(dek []byte, plaintext string, aad []byte) (string, error) {
+	sealed, err := seal(dek, []byte(plaintext), aad)
+	if err != nil {
+		return "", err
+	}
+	return SealedPrefix + sealed, nil
+}
+
+// Open reverses Seal. Values without the sealed prefix are returned as is,
+// so rows written before encryption was enabled stay readable.
+func Open(dek []byte, stored string, aad []byte) (string, error) {
+	if !IsSealed(stored) {
+		return stored, nil
+	}
+	plaintext, err := open(dek, strings.TrimPrefix(stored, SealedPrefix), aad)
+	return string(plaintext), err
+}
+
+// IsSealed reports whether a stored value was written by Seal.
+func IsSealed(stored string) bool {
+	return strings.HasPrefix(stored, SealedPrefix)
+}
+
+func seal(key, plaintext, aad []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plaintext, aad)), nil
+}
+
+func open(key []byte, encoded string, aad []byte) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < gcm.NonceSize() {
+		return nil, errors.New("sealed value is too short")
+	}
+	return gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,110 @@
+package events
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+)
+
+// Event types, also used as routing keys on the events exchange
+const (
+	MessageCreated = "message.created"
+	NoteViewed     = "note.viewed"
+	NoteExpired    = "note.expired"
+	// NoteFirstViewed follows the note.viewed of a note's first read
+	NoteFirstViewed = "note.first_viewed"
+	// NoteDestroyed is sent when too many wrong access keys burn a note
+	NoteDestroyed = "note.destroyed"
+)
+
+// WebhookTypes are the events users can subscribe webhooks to
+var WebhookTypes = []string{MessageCreated, NoteViewed, NoteFirstViewed, NoteExpired, NoteDestroyed}
+
+// Event is published to the events exchange and fanned out to consumers
+type Event struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	UserID    uuid.UUID       `json:"userId"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+func New(eventType string, userID uuid.UUID, data interface{}) (Event, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, err
+	}
+	return Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		UserID:    userID,
+		CreatedAt: time.Now(),
+		Data:      raw,
+	}, nil
+}
+
+// MessageData is the payload of message events
+type MessageData struct {
+	ID          uuid.UUID  `json:"id"`
+	Content     string     `json:"content"`
+	Folder      string     `json:"folder"`
+	PromptID    *uuid.UUID `json:"promptId,omitempty"`
+	IsEncrypted bool       `json:"isEncrypted"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+func NewMessageData(m models.Message) MessageData {
+	return MessageData{
+		ID:          m.ID,
+		Content:     m.Content,
+		Folder:      m.Folder,
+		PromptID:    m.PromptID,
+		IsEncrypted: m.IsEncrypted,
+		CreatedAt:   m.CreatedAt,
+	}
+}
+
+// NoteData is the payload of note events, it never carries the ciphertext
+type NoteData struct {
+	ID            uuid.UUID  `json:"id"`
+	Slug          string     `json:"slug"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	ExpiresAt     *time.Time `json:"expiresAt,omitempty"`
+	FirstViewedAt *time.Time `json:"firstViewedAt,omitempty"`
+	ViewCount     int        `json:"viewCount"`
+}
+
+func NewNoteData(n models.Note) NoteData {
+	return NoteData{
+		ID:            n.ID,
+		Slug:          n.Slug,
+		CreatedAt:     n.CreatedAt,
+		ExpiresAt:     n.ExpiresAt,
+		FirstViewedAt: n.FirstViewedAt,
+		ViewCount:     n.ViewCount,
+	}
+}
+
+// Publisher sends events to the events exchange
+type Publisher struct {
+	rmq      *queue.RabbitMQ
+	exchange string
+}
+
+func NewPublisher(rmq *queue.RabbitMQ, exchange string) *Publisher {
+	return &Publisher{rmq: rmq, exchange: exchange}
+}
+
+func (p *Publisher) Publish(e Event) error {
+	if p == nil || p.rmq == nil {
+		return nil
+	}
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return p.rmq.PublishExchange(p.exchange, e.Type, body)
+}
@@ -0,0 +1,99 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+)
+
+// subscriptionBuffer is how many events a slow client may lag behind before
+// events to it are dropped
+const subscriptionBuffer = 16
+
+// Subscription receives the events of one user
+type Subscription struct {
+	C      <-chan Event
+	c      chan Event
+	userID uuid.UUID
+}
+
+// Hub delivers events to the live connections of this server instance.
+// Every instance runs its own hub fed from the events exchange, so a client
+// gets its events whichever replica it is connected to.
+type Hub struct {
+	mu   sync.RWMutex
+	subs map[uuid.UUID]map[*Subscription]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{subs: map[uuid.UUID]map[*Subscription]struct{}{}}
+}
+
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	ch := make(chan Event, subscriptionBuffer)
+	sub := &Subscription{C: ch, c: ch, userID: userID}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[*Subscription]struct{}{}
+	}
+	h.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, ok := h.subs[sub.userID][sub]; !ok {
+		return
+	}
+	delete(h.subs[sub.userID], sub)
+	if len(h.subs[sub.userID]) == 0 {
+		delete(h.subs, sub.userID)
+	}
+	close(sub.c)
+}
+
+// Dispatch hands an event to the user's subscriptions without blocking
+func (h *Hub) Dispatch(e Event) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for sub := range h.subs[e.UserID] {
+		select {
+		case sub.c <- e:
+		default:
+			log.Printf("Dropping %s event for slow subscriber of user %s\n", e.Type, e.UserID)
+		}
+	}
+}
+
+// Run feeds the hub from the events exchange until the process exits,
+// resubscribing when the connection drops
+func (h *Hub) Run(rmq *queue.RabbitMQ, exchange string, keys ...string) {
+	for {
+		msgs, ch, err := rmq.Subscribe(exchange, keys...)
+		if err != nil {
+			log.Printf("Failed to subscribe to %s: %v\n", exchange, err)
+			time.Sleep(5 * time.Second)
+			continue
+		}
+
+		for d := range msgs {
+			var e Event
+			if err := json.Unmarshal(d.Body, &e); err != nil {
+				log.Printf("Invalid event format: %v\n", err)
+				continue
+			}
+			h.Dispatch(e)
+		}
+
+		ch.Close()
+		log.Printf("Event subscription to %s closed, resubscribing\n", exchange)
+		time.Sleep(time.Second)
+	}
+}
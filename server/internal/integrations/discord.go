@@ -0,0 +1,42 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Discord posts to a Discord channel webhook
+type Discord struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+const (
+	discordMaxTitle       = 256
+	discordMaxDescription = 4096
+)
+
+func (d *Discord) Notify(ctx context.Context, m Message) error {
+	title := "New anonymous message"
+	if m.PromptTitle != "" {
+		title += " on " + m.PromptTitle
+	}
+
+	embed := map[string]interface{}{
+		"title":       truncate(title, discordMaxTitle),
+		"description": truncate(body(m), discordMaxDescription),
+		"timestamp":   m.CreatedAt.UTC().Format(time.RFC3339),
+	}
+	if m.URL != "" {
+		embed["url"] = m.URL
+	}
+
+	payload := map[string]interface{}{
+		"username": "SilentEcho",
+		"embeds":   []interface{}{embed},
+		// Anonymous senders must not be able to ping @everyone
+		"allowed_mentions": map[string]interface{}{"parse": []string{}},
+	}
+	return sendJSON(ctx, d.Client, http.MethodPost, d.WebhookURL, nil, payload)
+}
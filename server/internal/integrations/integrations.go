@@ -0,0 +1,165 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+)
+
+// Supported integration kinds
+const (
+	KindSlack   = "slack"
+	KindDiscord = "discord"
+	KindMatrix  = "matrix"
+)
+
+// Kinds lists every supported integration kind
+var Kinds = []string{KindSlack, KindDiscord, KindMatrix}
+
+var ErrUnknownKind = errors.New("unknown integration kind")
+
+// Message is a new inbox message as it is forwarded to chat
+type Message struct {
+	ID          string
+	Content     string // empty when the content is withheld
+	PromptTitle string
+	IsEncrypted bool
+	CreatedAt   time.Time
+	URL         string // where the owner reads the message
+}
+
+// Notifier delivers messages to one chat destination
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// New returns the notifier for an integration
+func New(i models.Integration, client *http.Client) (Notifier, error) {
+	switch i.Kind {
+	case KindSlack:
+		return &Slack{WebhookURL: i.Config.WebhookURL, Client: client}, nil
+	case KindDiscord:
+		return &Discord{WebhookURL: i.Config.WebhookURL, Client: client}, nil
+	case KindMatrix:
+		return &Matrix{
+			Homeserver:  i.Config.Homeserver,
+			RoomID:      i.Config.RoomID,
+			AccessToken: i.Config.AccessToken,
+			Client:      client,
+		}, nil
+	}
+	return nil, ErrUnknownKind
+}
+
+// Validate checks the destination of an integration. Private hosts are only
+// allowed with allowPrivate, e.g. for local stubs.
+func Validate(kind string, cfg models.IntegrationConfig, allowPrivate bool) error {
+	switch kind {
+	case KindSlack, KindDiscord:
+		return webhook.ValidateURL(cfg.WebhookURL, allowPrivate)
+	case KindMatrix:
+		if err := webhook.ValidateURL(cfg.Homeserver, allowPrivate); err != nil {
+			return err
+		}
+		if !strings.HasPrefix(cfg.RoomID, "!") || !strings.Contains(cfg.RoomID, ":") {
+			return errors.New("room ID must look like !room:server")
+		}
+		if cfg.AccessToken == "" {
+			return errors.New("access token is required")
+		}
+		return nil
+	}
+	return ErrUnknownKind
+}
+
+// Describe names the destination without revealing any credential
+func Describe(i models.Integration) string {
+	switch i.Kind {
+	case KindSlack, KindDiscord:
+		if u, err := url.Parse(i.Config.WebhookURL); err == nil {
+			return u.Host
+		}
+	case KindMatrix:
+		return i.Config.RoomID
+	}
+	return ""
+}
+
+// Text renders a message as plain text
+func Text(m Message) string {
+	var b strings.Builder
+	b.WriteString("New anonymous message")
+	if m.PromptTitle != "" {
+		fmt.Fprintf(&b, " on %q", m.PromptTitle)
+	}
+	b.WriteString("\n\n")
+	b.WriteString(body(m))
+	if m.URL != "" {
+		b.WriteString("\n\n")
+		b.WriteString(m.URL)
+	}
+	return b.String()
+}
+
+// body is the message content or a placeholder when it cannot be shown
+func body(m Message) string {
+	switch {
+	case m.IsEncrypted:
+		return "This message is end-to-end encrypted, open SilentEcho to read it."
+	case m.Content == "":
+		return "Open SilentEcho to read it."
+	}
+	return m.Content
+}
+
+// truncate shortens s to at most n runes, platforms reject longer fields
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// sendJSON sends payload and treats any 2xx response as delivered
+func sendJSON(ctx context.Context, client *http.Client, method, endpoint string, header http.Header, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SilentEcho-Integrations/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if detail := strings.TrimSpace(string(reply)); detail != "" {
+			return fmt.Errorf("%s: %s", resp.Status, detail)
+		}
+		return errors.New(resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// request is what a stub chat server received
+type request struct {
+	Method string
+	Path   string
+	Header http.Header
+	Body   map[string]interface{}
+}
+
+// stubServer records the one request it gets and answers with status and reply
+func stubServer(t *testing.T, status int, reply string) (*httptest.Server, *request) {
+	t.Helper()
+	got := &request{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		raw, _ := io.ReadAll(r.Body)
+		got.Method, got.Path, got.Header = r.Method, r.URL.Path, r.Header
+		if err := json.Unmarshal(raw, &got.Body); err != nil {
+			t.Errorf("body is not JSON: %q", raw)
+		}
+		w.WriteHeader(status)
+		io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, got
+}
+
+var testMessage = Message{
+	ID:          "42",
+	Content:     "hi <b>there</b> & @everyone",
+	PromptTitle: "Ask me",
+	CreatedAt:   time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+	URL:         "https://app.example/dashboard/messages",
+}
+
+func TestSlackNotify(t *testing.T) {
+	srv, got := stubServer(t, http.StatusOK, "ok")
+	s := &Slack{WebhookURL: srv.URL + "/services/T/B/X", Client: srv.Client()}
+	if err := s.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != http.MethodPost || got.Path != "/services/T/B/X" || got.Header.Get("Content-Type") != "application/json" {
+		t.Errorf("request = %s %s %q", got.Method, got.Path, got.Header.Get("Content-Type"))
+	}
+	if got.Body["text"] != Text(testMessage) {
+		t.Errorf("text = %q", got.Body["text"])
+	}
+	blocks, _ := got.Body["blocks"].([]interface{})
+	if len(blocks) != 2 {
+		t.Fatalf("blocks = %v", got.Body["blocks"])
+	}
+	section := blocks[0].(map[string]interface{})["text"].(map[string]interface{})["text"].(string)
+	if want := "*New anonymous message on Ask me*\nhi &lt;b&gt;there&lt;/b&gt; &amp; @everyone"; section != want {
+		t.Errorf("section = %q, want %q", section, want)
+	}
+	link := blocks[1].(map[string]interface{})["elements"].([]interface{})[0].(map[string]interface{})["text"]
+	if link != "<"+testMessage.URL+"|Open SilentEcho>" {
+		t.Errorf("link = %q", link)
+	}
+}
+
+func TestDiscordNotify(t *testing.T) {
+	srv, got := stubServer(t, http.StatusNoContent, "")
+	d := &Discord{WebhookURL: srv.URL + "/api/webhooks/1/abc", Client: srv.Client()}
+	m := testMessage
+	m.Content = strings.Repeat("x", discordMaxDescription+10)
+	if err := d.Notify(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != http.MethodPost || got.Path != "/api/webhooks/1/abc" {
+		t.Errorf("request = %s %s", got.Method, got.Path)
+	}
+	mentions, _ := got.Body["allowed_mentions"].(map[string]interface{})
+	if parse, ok := mentions["parse"].([]interface{}); !ok || len(parse) != 0 {
+		t.Errorf("allowed_mentions = %v, mentions must be disabled", got.Body["allowed_mentions"])
+	}
+	embeds, _ := got.Body["embeds"].([]interface{})
+	if len(embeds) != 1 {
+		t.Fatalf("embeds = %v", got.Body["embeds"])
+	}
+	embed := embeds[0].(map[string]interface{})
+	if embed["title"] != "New anonymous message on Ask me" || embed["url"] != m.URL || embed["timestamp"] != "2025-01-02T03:04:05Z" {
+		t.Errorf("embed = %v", embed)
+	}
+	if n := len([]rune(embed["description"].(string))); n != discordMaxDescription {
+		t.Errorf("description has %d characters, want %d", n, discordMaxDescription)
+	}
+}
+
+func TestMatrixNotify(t *testing.T) {
+	srv, got := stubServer(t, http.StatusOK, `{"event_id":"$1"}`)
+	mx := &Matrix{Homeserver: srv.URL + "/", RoomID: "!room:example.org", AccessToken: "secret", Client: srv.Client()}
+	if err := mx.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Method != http.MethodPut || got.Path != "/_matrix/client/v3/rooms/!room:example.org/send/m.room.message/silentecho-42" {
+		t.Errorf("request = %s %s", got.Method, got.Path)
+	}
+	if got.Header.Get("Authorization") != "Bearer secret" {
+		t.Errorf("Authorization = %q", got.Header.Get("Authorization"))
+	}
+	if got.Body["msgtype"] != "m.notice" || got.Body["body"] != Text(testMessage) {
+		t.Errorf("body = %v", got.Body)
+	}
+	formatted, _ := got.Body["formatted_body"].(string)
+	if !strings.Contains(formatted, "hi &lt;b&gt;there&lt;/b&gt; &amp; @everyone") || strings.Contains(formatted, "<b>there") {
+		t.Errorf("formatted_body is not escaped: %q", formatted)
+	}
+}
+
+func TestNotifyWithholdsEncryptedContent(t *testing.T) {
+	srv, got := stubServer(t, http.StatusOK, "ok")
+	m := testMessage
+	m.IsEncrypted = true
+	m.Content = "c2VhbGVk"
+	if err := (&Slack{WebhookURL: srv.URL, Client: srv.Client()}).Notify(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	if text, _ := got.Body["text"].(string); strings.Contains(text, m.Content) || !strings.Contains(text, "end-to-end encrypted") {
+		t.Errorf("text = %q", text)
+	}
+}
+
+func TestNotifyErrors(t *testing.T) {
+	notifiers := map[string]func(url string, c *http.Client) Notifier{
+		KindSlack:   func(url string, c *http.Client) Notifier { return &Slack{WebhookURL: url, Client: c} },
+		KindDiscord: func(url string, c *http.Client) Notifier { return &Discord{WebhookURL: url, Client: c} },
+		KindMatrix: func(url string, c *http.Client) Notifier {
+			return &Matrix{Homeserver: url, RoomID: "!room:example.org", AccessToken: "secret", Client: c}
+		},
+	}
+	tests := []struct {
+		status int
+		reply  string
+		want   string
+	}{
+		{http.StatusBadRequest, "invalid_payload\n", "400 Bad Request: invalid_payload"},
+		{http.StatusInternalServerError, "", "500 Internal Server Error"},
+	}
+	for kind, newNotifier := range notifiers {
+		for _, tt := range tests {
+			srv, _ := stubServer(t, tt.status, tt.reply)
+			err := newNotifier(srv.URL, srv.Client()).Notify(context.Background(), testMessage)
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("%s answered %d: err = %v, want %q", kind, tt.status, err, tt.want)
+			}
+		}
+	}
+
+	// Unreachable destinations fail too
+	srv, _ := stubServer(t, http.StatusOK, "")
+	srv.Close()
+	if err := (&Slack{WebhookURL: srv.URL, Client: srv.Client()}).Notify(context.Background(), testMessage); err == nil {
+		t.Error("closed server: no error")
+	}
+}
@@ -0,0 +1,43 @@
+package integrations
+
+import (
+	"context"
+	"html"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Matrix sends a notice to a room through the client-server API
+type Matrix struct {
+	Homeserver  string
+	RoomID      string
+	AccessToken string
+	Client      *http.Client
+}
+
+func (mx *Matrix) Notify(ctx context.Context, m Message) error {
+	// The message ID is the transaction ID, so retries are not posted twice
+	endpoint := strings.TrimRight(mx.Homeserver, "/") +
+		"/_matrix/client/v3/rooms/" + url.PathEscape(mx.RoomID) +
+		"/send/m.room.message/silentecho-" + url.PathEscape(m.ID)
+
+	title := "New anonymous message"
+	if m.PromptTitle != "" {
+		title += " on " + m.PromptTitle
+	}
+	formatted := "<strong>" + html.EscapeString(title) + "</strong><br>" +
+		strings.ReplaceAll(html.EscapeString(body(m)), "\n", "<br>")
+	if m.URL != "" {
+		formatted += `<br><a href="` + html.EscapeString(m.URL) + `">Open SilentEcho</a>`
+	}
+
+	payload := map[string]string{
+		"msgtype":        "m.notice",
+		"body":           Text(m),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted,
+	}
+	header := http.Header{"Authorization": {"Bearer " + mx.AccessToken}}
+	return sendJSON(ctx, mx.Client, http.MethodPut, endpoint, header, payload)
+}
@@ -0,0 +1,49 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+// Slack posts to a Slack incoming webhook
+type Slack struct {
+	WebhookURL string
+	Client     *http.Client
+}
+
+// Section blocks hold at most 3000 characters
+const slackMaxText = 3000
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+func (s *Slack) Notify(ctx context.Context, m Message) error {
+	title := "*New anonymous message*"
+	if m.PromptTitle != "" {
+		title = "*New anonymous message on " + slackEscaper.Replace(m.PromptTitle) + "*"
+	}
+
+	blocks := []map[string]interface{}{
+		{
+			"type": "section",
+			"text": map[string]string{
+				"type": "mrkdwn",
+				"text": truncate(title+"\n"+slackEscaper.Replace(body(m)), slackMaxText),
+			},
+		},
+	}
+	if m.URL != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "context",
+			"elements": []map[string]string{
+				{"type": "mrkdwn", "text": "<" + m.URL + "|Open SilentEcho>"},
+			},
+		})
+	}
+
+	payload := map[string]interface{}{
+		"text":   truncate(Text(m), slackMaxText),
+		"blocks": blocks,
+	}
+	return sendJSON(ctx, s.Client, http.MethodPost, s.WebhookURL, nil, payload)
+}
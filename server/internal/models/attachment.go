@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment upload states
+const (
+	AttachmentUploading = "uploading"
+	AttachmentComplete  = "complete"
+)
+
+// Attachment is a client-side encrypted file stored as numbered chunks in the
+// blob store. Its note becomes nil when the note is deleted, the blobs are then
+// purged by the cleanup worker.
+type Attachment struct {
+	ID     uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	NoteID *uuid.UUID `json:"-" gorm:"type:uuid;index"`
+	// The file name is encrypted by the client like the note itself
+	EncryptedName string     `json:"encryptedName" gorm:"type:text"`
+	Size          int64      `json:"size" gorm:"not null"`
+	ChunkCount    int        `json:"chunkCount" gorm:"not null"`
+	Status        string     `json:"status" gorm:"size:16;not null;default:uploading;index"`
+	CreatedAt     time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	CompletedAt   *time.Time `json:"completedAt,omitempty"`
+	Note          *Note      `json:"-" gorm:"foreignKey:NoteID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+}
+
+// AttachmentChunk records an uploaded chunk of an attachment
+type AttachmentChunk struct {
+	AttachmentID uuid.UUID  `json:"-" gorm:"type:uuid;primaryKey"`
+	Index        int        `json:"index" gorm:"column:position;primaryKey;autoIncrement:false"`
+	Size         int64      `json:"size" gorm:"not null"`
+	Attachment   Attachment `json:"-" gorm:"foreignKey:AttachmentID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
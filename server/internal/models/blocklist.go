@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BlockedWord is a recipient-defined keyword, messages containing it are dropped
+type BlockedWord struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_blocked_word"`
+	Word      string    `json:"word" gorm:"not null;uniqueIndex:idx_blocked_word"`
+	HitCount  int64     `json:"hitCount" gorm:"not null;default:0"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// BlockedSender holds the salted fingerprint of a sender, never the raw IP or user agent
+type BlockedSender struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID      uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_blocked_sender"`
+	Fingerprint string    `json:"-" gorm:"size:64;not null;uniqueIndex:idx_blocked_sender"`
+	HitCount    int64     `json:"hitCount" gorm:"not null;default:0"`
+	CreatedAt   time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	User        User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,9 @@
+package models
+
+import "time"
+
+// SpentChallenge records a solved challenge so it cannot be replayed
+type SpentChallenge struct {
+	ID        string    `gorm:"size:32;primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
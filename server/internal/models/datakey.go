@@ -0,0 +1,17 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DataKey is a user's content encryption key, wrapped by a master key
+type DataKey struct {
+	UserID      uuid.UUID `gorm:"type:uuid;primaryKey"`
+	WrappedKey  string    `gorm:"not null"`
+	MasterKeyID string    `gorm:"size:32;not null;index"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+	User        User      `gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IntegrationConfig holds the destination of a chat integration, only the
+// fields of its kind are set
+type IntegrationConfig struct {
+	WebhookURL  string `json:"webhookUrl,omitempty"`
+	Homeserver  string `json:"homeserver,omitempty"`
+	RoomID      string `json:"roomId,omitempty"`
+	AccessToken string `json:"accessToken,omitempty"`
+}
+
+// Integration forwards new inbox messages to a Slack, Discord or Matrix room
+type Integration struct {
+	ID     uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	Kind   string    `json:"kind" gorm:"size:16;not null"`
+	Name   string    `json:"name"`
+	// Webhook URLs and access tokens are credentials, they are never returned
+	Config IntegrationConfig `json:"-" gorm:"serializer:json;not null"`
+	// Only announce that a message arrived, without its content
+	HideContent     bool       `json:"hideContent" gorm:"not null;default:false"`
+	IsActive        bool       `json:"isActive" gorm:"not null;default:true"`
+	LastError       string     `json:"lastError,omitempty"`
+	LastDeliveredAt *time.Time `json:"lastDeliveredAt,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt       time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+	User            User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
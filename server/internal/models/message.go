@@ -4,12 +4,76 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Message folders
+const (
+	FolderInbox      = "inbox"
+	FolderQuarantine = "quarantine"
 )
 
 type Message struct {
-	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	UserID    uuid.UUID `json:"userId" gorm:"type:uuid;not null;index"`
-	Content   string    `json:"content" gorm:"type:text;not null"`
-	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
-	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID                uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID            uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	Content           string     `json:"content" gorm:"type:text;not null"`
+	PromptID          *uuid.UUID `json:"promptId,omitempty" gorm:"type:uuid;index"`
+	Folder            string     `json:"folder" gorm:"size:16;not null;default:inbox;index"`
+	ModerationReason  string     `json:"moderationReason,omitempty"`
+	SenderFingerprint string     `json:"-" gorm:"size:64;index"`
+	SimHash           int64      `json:"-" gorm:"not null;default:0"`
+	IsEncrypted       bool       `json:"isEncrypted" gorm:"not null;default:false"`
+	IsPublic          bool       `json:"isPublic" gorm:"not null;default:false;index"`
+	PublishedAt       *time.Time `json:"publishedAt,omitempty"`
+	ThreadTokenHash   *string    `json:"-" gorm:"size:64;uniqueIndex"`
+	HasThread         bool       `json:"hasThread" gorm:"not null;default:false"`
+	CreatedAt         time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	Replies           []Reply    `json:"replies,omitempty" gorm:"foreignKey:MessageID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Prompt            *Prompt    `json:"-" gorm:"foreignKey:PromptID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	User              User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// plaintext holds Content while the sealed value is being written
+	plaintext string
+}
+
+// ContentSealer encrypts message content at rest
+type ContentSealer interface {
+	Seal(tx *gorm.DB, userID uuid.UUID, plaintext string) (string, error)
+	Open(tx *gorm.DB, userID uuid.UUID, stored string) (string, error)
+}
+
+// MessageSealer is installed by the repositories package when master keys
+// are configured. End-to-end encrypted messages are already ciphertext and
+// are left alone.
+var MessageSealer ContentSealer
+
+func (m *Message) BeforeSave(tx *gorm.DB) error {
+	if MessageSealer == nil || m.IsEncrypted || m.Content == "" {
+		return nil
+	}
+	sealed, err := MessageSealer.Seal(tx, m.UserID, m.Content)
+	if err != nil {
+		return err
+	}
+	m.plaintext, m.Content = m.Content, sealed
+	return nil
+}
+
+func (m *Message) AfterSave(tx *gorm.DB) error {
+	if m.plaintext != "" {
+		m.Content, m.plaintext = m.plaintext, ""
+	}
+	return nil
+}
+
+func (m *Message) AfterFind(tx *gorm.DB) error {
+	if MessageSealer == nil || m.IsEncrypted {
+		return nil
+	}
+	content, err := MessageSealer.Open(tx, m.UserID, m.Content)
+	if err != nil {
+		return err
+	}
+	m.Content = content
+	return nil
 }
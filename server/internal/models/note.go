@@ -7,11 +7,102 @@ import (
 )
 
 type Note struct {
-	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Slug       string     `json:"slug" gorm:"uniqueIndex;not null"`
-	CipherNote string     `json:"ciphernote" gorm:"not null"`
-	UserID     uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
-	CreatedAt  time.Time  `json:"createdAt" gorm:"autoCreateTime"`
-	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
-	User       User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	ID         uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Slug       string    `json:"slug" gorm:"uniqueIndex;not null"`
+	CipherNote string    `json:"ciphernote" gorm:"not null"`
+	// How CipherNote was encrypted. Version 0 rows predate envelopes and hold
+	// the legacy base64(salt|iv|ciphertext) blob.
+	EnvelopeVersion int    `json:"-" gorm:"not null;default:0"`
+	KDF             string `json:"-" gorm:"size:32"`
+	KDFIterations   int    `json:"-"`
+	KDFMemory       int    `json:"-"`
+	KDFParallelism  int    `json:"-"`
+	Salt            string `json:"-"`
+	IV              string `json:"-"`
+	// Encrypted by the owner's client, the server never sees titles
+	Title    string      `json:"title,omitempty" gorm:"type:text"`
+	FolderID *uuid.UUID  `json:"folderId,omitempty" gorm:"type:uuid;index"`
+	Folder   *NoteFolder `json:"-" gorm:"foreignKey:FolderID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Labels   []NoteLabel `json:"labels,omitempty" gorm:"foreignKey:NoteID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	// Notes created for recipients can only be read through their shares
+	RecipientsOnly bool `json:"recipientsOnly" gorm:"not null;default:false"`
+	// Nil for notes created without an account
+	UserID    *uuid.UUID `json:"userId,omitempty" gorm:"type:uuid;index"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	// Drafts cannot be found by slug until published, scheduled notes can
+	// be found but not read before NotBefore
+	Draft     bool       `json:"draft" gorm:"not null;default:false"`
+	NotBefore *time.Time `json:"notBefore,omitempty"`
+	// Reads left are MaxViews - ViewCount, unlimited when MaxViews is nil
+	MaxViews  *int       `json:"maxViews,omitempty"`
+	ViewCount int        `json:"viewCount" gorm:"not null;default:0"`
+	RevokedAt *time.Time `json:"revokedAt,omitempty"`
+	// Owners may ask for an email on the first read
+	FirstViewedAt *time.Time `json:"firstViewedAt,omitempty"`
+	NotifyOnView  bool       `json:"notifyOnView" gorm:"not null;default:false"`
+	// Anonymous creators manage their note with the matching token
+	ManagementTokenHash *string `json:"-" gorm:"uniqueIndex"`
+	// Gated notes release the ciphertext only to a matching access key and
+	// are destroyed after MaxAttempts wrong ones
+	AccessKeyHash  *string    `json:"-"`
+	FailedAttempts int        `json:"failedAttempts" gorm:"not null;default:0"`
+	MaxAttempts    int        `json:"maxAttempts" gorm:"not null;default:0"`
+	DestroyedAt    *time.Time `json:"destroyedAt,omitempty"`
+	// Set once note.expired has been published for this note
+	ExpiryAnnounced bool `json:"-" gorm:"not null;default:false"`
+	User            User `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Note states as reported to readers
+const (
+	NoteActive    = "active"
+	NoteRevoked   = "revoked"
+	NoteExpired   = "expired"
+	NoteExhausted = "exhausted"
+	NoteDestroyed = "destroyed"
+	NoteDraft     = "draft"
+	NoteScheduled = "scheduled"
+)
+
+// Status reports whether the note can still be read at now
+func (n Note) Status(now time.Time) string {
+	switch {
+	case n.DestroyedAt != nil:
+		return NoteDestroyed
+	case n.RevokedAt != nil:
+		return NoteRevoked
+	case n.ExpiresAt != nil && !now.Before(*n.ExpiresAt):
+		return NoteExpired
+	case n.MaxViews != nil && n.ViewCount >= *n.MaxViews:
+		return NoteExhausted
+	case n.Draft:
+		return NoteDraft
+	case n.NotBefore != nil && now.Before(*n.NotBefore):
+		return NoteScheduled
+	}
+	return NoteActive
+}
+
+// Live reports whether the note is readable at now or will be once it is
+// published or released, so its owner may still attach files or share it
+func (n Note) Live(now time.Time) bool {
+	switch n.Status(now) {
+	case NoteActive, NoteDraft, NoteScheduled:
+		return true
+	}
+	return false
+}
+
+// Gated reports whether reading the note needs an access key
+func (n Note) Gated() bool {
+	return n.AccessKeyHash != nil
+}
+
+// Wipe erases the ciphertext along with the salt and IV needed to decrypt it,
+// as revoked and destroyed notes are never read again. It returns the same
+// change as column updates for callers that do not save the whole note.
+func (n *Note) Wipe() map[string]interface{} {
+	n.CipherNote, n.Salt, n.IV = "", "", ""
+	return map[string]interface{}{"cipher_note": "", "salt": "", "iv": ""}
 }
@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NoteFolder groups an owner's notes. Deleting one leaves its notes unfiled.
+type NoteFolder struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_note_folders_user_name"`
+	Name      string    `json:"name" gorm:"size:64;not null;uniqueIndex:idx_note_folders_user_name"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,10 @@
+package models
+
+import "github.com/google/uuid"
+
+// NoteLabel tags a note for filtering. Labels are plaintext so the server can
+// filter on them, unlike note titles.
+type NoteLabel struct {
+	NoteID uuid.UUID `json:"-" gorm:"type:uuid;primaryKey"`
+	Label  string    `json:"label" gorm:"size:32;primaryKey;index"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NoteRecipient gives a SilentEcho user access to a note. WrappedKey is the
+// note key sealed to PublicKey, the recipient's key at the time of sharing.
+type NoteRecipient struct {
+	ID           uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	NoteID       uuid.UUID  `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_note_recipients_note_user"`
+	UserID       uuid.UUID  `json:"-" gorm:"type:uuid;not null;uniqueIndex:idx_note_recipients_note_user;index"`
+	PublicKey    string     `json:"publicKey" gorm:"size:64;not null"`
+	WrappedKey   string     `json:"-" gorm:"type:text;not null"`
+	CreatedAt    time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	LastViewedAt *time.Time `json:"lastViewedAt,omitempty"`
+	Note         Note       `json:"-" gorm:"foreignKey:NoteID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User         User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NoteView records one successful read of a note. The IP is only kept as a
+// salted hash scoped to the note.
+type NoteView struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	NoteID    uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	IPHash    string    `json:"ipHash" gorm:"size:64;not null"`
+	UserAgent string    `json:"userAgent"`
+	Country   string    `json:"country,omitempty" gorm:"size:2"`
+	ViewedAt  time.Time `json:"viewedAt" gorm:"autoCreateTime;index"`
+	Note      Note      `json:"-" gorm:"foreignKey:NoteID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
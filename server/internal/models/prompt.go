@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Prompt is a named inbox link, e.g. "Ask me anything", with its own public slug
+type Prompt struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	Slug      string     `json:"slug" gorm:"uniqueIndex;not null"`
+	Title     string     `json:"title" gorm:"not null"`
+	Question  string     `json:"question,omitempty" gorm:"type:text"`
+	IsOpen    bool       `json:"isOpen" gorm:"not null;default:true"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
+	User      User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// AcceptsMessages reports whether the prompt is open and not expired
+func (p Prompt) AcceptsMessages(now time.Time) bool {
+	return p.IsOpen && (p.ExpiresAt == nil || now.Before(*p.ExpiresAt))
+}
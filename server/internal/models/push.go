@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PushSubscription is a browser registered for Web Push notifications
+type PushSubscription struct {
+	ID         uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID     uuid.UUID  `json:"-" gorm:"type:uuid;not null;index"`
+	Endpoint   string     `json:"endpoint" gorm:"type:text;not null;uniqueIndex"`
+	P256dh     string     `json:"-" gorm:"not null"`
+	Auth       string     `json:"-" gorm:"not null"`
+	UserAgent  string     `json:"userAgent,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt" gorm:"autoCreateTime"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	User       User       `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Reply struct {
+	ID          uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	MessageID   uuid.UUID `json:"messageId" gorm:"type:uuid;not null;index"`
+	UserID      uuid.UUID `json:"userId" gorm:"type:uuid;not null;index"`
+	Content     string    `json:"content" gorm:"type:text;not null"`
+	FromSender  bool      `json:"fromSender" gorm:"not null;default:false"`
+	IsEncrypted bool      `json:"isEncrypted" gorm:"not null;default:false"`
+	// IsPublic marks answers the owner published with the message, every
+	// other reply stays between the owner and the sender
+	IsPublic  bool      `json:"isPublic" gorm:"not null;default:false"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+
+	// plaintext holds Content while the sealed value is being written
+	plaintext string
+}
+
+// Replies are sealed with the inbox owner's data key, like the messages they answer
+
+func (r *Reply) BeforeSave(tx *gorm.DB) error {
+	if MessageSealer == nil || r.IsEncrypted || r.Content == "" {
+		return nil
+	}
+	sealed, err := MessageSealer.Seal(tx, r.UserID, r.Content)
+	if err != nil {
+		return err
+	}
+	r.plaintext, r.Content = r.Content, sealed
+	return nil
+}
+
+func (r *Reply) AfterSave(tx *gorm.DB) error {
+	if r.plaintext != "" {
+		r.Content, r.plaintext = r.plaintext, ""
+	}
+	return nil
+}
+
+func (r *Reply) AfterFind(tx *gorm.DB) error {
+	if MessageSealer == nil || r.IsEncrypted {
+		return nil
+	}
+	content, err := MessageSealer.Open(tx, r.UserID, r.Content)
+	if err != nil {
+		return err
+	}
+	r.Content = content
+	return nil
+}
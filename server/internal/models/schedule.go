@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AcceptWindow is a weekly time range, in the schedule's timezone, during
+// which messages are accepted. Days use time.Weekday numbering (0 = Sunday)
+// and an End at or before Start means the window runs past midnight.
+type AcceptWindow struct {
+	Days  []time.Weekday `json:"days"`
+	Start string         `json:"start"` // "HH:MM"
+	End   string         `json:"end"`   // "HH:MM"
+}
+
+// AcceptSchedule narrows when a user's inbox is open, on top of IsAcceptingMessages
+type AcceptSchedule struct {
+	UserID      uuid.UUID      `json:"-" gorm:"type:uuid;primaryKey"`
+	Timezone    string         `json:"timezone" gorm:"not null;default:UTC"`
+	Windows     []AcceptWindow `json:"windows" gorm:"serializer:json"`
+	MaxMessages *int           `json:"maxMessages,omitempty"`
+	CloseAt     *time.Time     `json:"closeAt,omitempty"`
+	// Messages counted towards MaxMessages are those received since CountFrom
+	CountFrom time.Time `json:"countFrom"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
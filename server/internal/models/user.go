@@ -15,6 +15,14 @@ type User struct {
 	VerifyCodeExpiry    *time.Time `json:"verifyCodeExpiry,omitempty"`
 	IsVerified          bool       `json:"isVerified" gorm:"not null;default:false"`
 	IsAcceptingMessages bool       `json:"isAcceptingMessages" gorm:"not null;default:true"`
+	ModerationLevel     string     `json:"moderationLevel" gorm:"size:16;not null;default:medium"`
+	BlockLinks          bool       `json:"blockLinks" gorm:"not null;default:false"`
+	DroppedMessages     int64      `json:"droppedMessages" gorm:"not null;default:0"`
+	RequireChallenge    bool       `json:"requireChallenge" gorm:"not null;default:false"`
+	PublicKey           string     `json:"publicKey,omitempty" gorm:"size:64"`
+	PublicKeyUpdatedAt  *time.Time `json:"publicKeyUpdatedAt,omitempty"`
+	NotifyMode          string     `json:"notifyMode" gorm:"size:16;not null;default:off"`
+	LastNotifiedAt      *time.Time `json:"-"`
 	CreatedAt           time.Time  `json:"createdAt" gorm:"autoCreateTime"`
 	UpdatedAt           time.Time  `json:"updatedAt" gorm:"autoUpdateTime"`
 }
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Webhook delivery statuses
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryFailed    = "failed"
+)
+
+// Webhook is a user-managed endpoint that receives signed event payloads
+type Webhook struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	UserID    uuid.UUID `json:"-" gorm:"type:uuid;not null;index"`
+	URL       string    `json:"url" gorm:"not null"`
+	Secret    string    `json:"-" gorm:"not null"`
+	Events    []string  `json:"events" gorm:"serializer:json;not null"`
+	IsActive  bool      `json:"isActive" gorm:"not null;default:true"`
+	CreatedAt time.Time `json:"createdAt" gorm:"autoCreateTime"`
+	UpdatedAt time.Time `json:"updatedAt" gorm:"autoUpdateTime"`
+	User      User      `json:"-" gorm:"foreignKey:UserID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// Subscribes reports whether the endpoint wants events of eventType
+func (w Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery logs one event sent to one endpoint, across all its attempts
+type WebhookDelivery struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	WebhookID      uuid.UUID  `json:"webhookId" gorm:"type:uuid;not null;index"`
+	EventID        uuid.UUID  `json:"eventId" gorm:"type:uuid;not null"`
+	EventType      string     `json:"eventType" gorm:"not null"`
+	Payload        string     `json:"-" gorm:"type:text;not null"`
+	Status         string     `json:"status" gorm:"size:16;not null;default:pending;index"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	LastStatusCode int        `json:"lastStatusCode,omitempty"`
+	LastError      string     `json:"lastError,omitempty"`
+	NextAttemptAt  *time.Time `json:"nextAttemptAt,omitempty"`
+	DeliveredAt    *time.Time `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt" gorm:"autoCreateTime;index"`
+	Webhook        Webhook    `json:"-" gorm:"foreignKey:WebhookID;references:ID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+}
+
+// SealWebhookPayload encrypts a delivery payload with the owner's data key,
+// as message events carry message content. Without master keys configured
+// payloads are stored like messages are, in plaintext.
+func SealWebhookPayload(tx *gorm.DB, userID uuid.UUID, payload string) (string, error) {
+	if MessageSealer == nil {
+		return payload, nil
+	}
+	return MessageSealer.Seal(tx, userID, payload)
+}
+
+// OpenWebhookPayload reverses SealWebhookPayload, payloads stored before
+// sealing are returned as they are
+func OpenWebhookPayload(tx *gorm.DB, userID uuid.UUID, stored string) (string, error) {
+	if MessageSealer == nil {
+		return stored, nil
+	}
+	return MessageSealer.Open(tx, userID, stored)
+}
@@ -0,0 +1,29 @@
+package moderation
+
+import "regexp"
+
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+|\b[a-z0-9\-]+\.(?:com|net|org|io|ly|gg|me|xyz|co|info|link|app)(?:/\S*)?\b`)
+
+// LinkFilter holds back messages with links for recipients that opted in.
+type LinkFilter struct{}
+
+func NewLinkFilter() *LinkFilter {
+	return &LinkFilter{}
+}
+
+// CountLinks returns how many links appear in content.
+func CountLinks(content string) int {
+	return len(linkPattern.FindAllStringIndex(content, -1))
+}
+
+func (f *LinkFilter) Moderate(content string, settings Settings) Result {
+	if !settings.BlockLinks || CountLinks(content) == 0 {
+		return Result{Verdict: Allow}
+	}
+
+	verdict := Quarantine
+	if settings.Sensitivity == SensitivityHigh {
+		verdict = Reject
+	}
+	return Result{Verdict: verdict, Filter: "links", Reason: "Links are blocked by the recipient"}
+}
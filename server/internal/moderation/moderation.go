@@ -0,0 +1,112 @@
+package moderation
+
+import "strings"
+
+// Verdict is the outcome of running a message through moderation.
+// Verdicts are ordered by severity so the strictest one can win.
+type Verdict int
+
+const (
+	Allow Verdict = iota
+	Quarantine
+	Reject
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Quarantine:
+		return "quarantine"
+	case Reject:
+		return "reject"
+	default:
+		return "allow"
+	}
+}
+
+// Sensitivity is the per-recipient strictness level.
+type Sensitivity string
+
+const (
+	SensitivityOff    Sensitivity = "off"
+	SensitivityLow    Sensitivity = "low"
+	SensitivityMedium Sensitivity = "medium"
+	SensitivityHigh   Sensitivity = "high"
+)
+
+// ParseSensitivity normalises a user supplied level, reporting whether it is known.
+func ParseSensitivity(s string) (Sensitivity, bool) {
+	switch level := Sensitivity(strings.ToLower(strings.TrimSpace(s))); level {
+	case SensitivityOff, SensitivityLow, SensitivityMedium, SensitivityHigh:
+		return level, true
+	}
+	return SensitivityMedium, false
+}
+
+// Settings are the recipient preferences a Moderator evaluates against.
+type Settings struct {
+	Sensitivity Sensitivity
+	BlockLinks  bool
+}
+
+// Result describes why a message got its verdict.
+type Result struct {
+	Verdict Verdict
+	Filter  string
+	Reason  string
+}
+
+// Moderator inspects message content before it is stored.
+type Moderator interface {
+	Moderate(content string, settings Settings) Result
+}
+
+// Chain runs every moderator and keeps the most severe result.
+type Chain []Moderator
+
+func (c Chain) Moderate(content string, settings Settings) Result {
+	result := Result{Verdict: Allow}
+	if settings.Sensitivity == SensitivityOff {
+		return result
+	}
+
+	for _, m := range c {
+		r := m.Moderate(content, settings)
+		if r.Verdict > result.Verdict {
+			result = r
+		}
+		if result.Verdict == Reject {
+			break
+		}
+	}
+	return result
+}
+
+// Default returns the built-in filters used by the messages handler.
+func Default() Moderator {
+	return Chain{
+		NewWordlistFilter(DefaultWordlist),
+		NewRegexFilter(DefaultPatterns),
+		NewLinkFilter(),
+	}
+}
+
+// escalate maps a severity hit to a verdict for the given sensitivity.
+// Severe hits are stricter than mild ones at every level.
+func escalate(severe bool, s Sensitivity) Verdict {
+	switch s {
+	case SensitivityLow:
+		if severe {
+			return Quarantine
+		}
+		return Allow
+	case SensitivityMedium:
+		if severe {
+			return Reject
+		}
+		return Quarantine
+	case SensitivityHigh:
+		return Reject
+	default:
+		return Allow
+	}
+}
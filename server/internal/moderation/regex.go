@@ -0,0 +1,75 @@
+package moderation
+
+import "regexp"
+
+// Pattern is a regular expression with the verdict it triggers. Valid, when
+// set, must also accept a match for it to count.
+type Pattern struct {
+	Name   string
+	Expr   *regexp.Regexp
+	Severe bool
+	Valid  func(match string) bool
+}
+
+// DefaultPatterns catch personal details that are commonly used to dox
+// the recipient or the people around them.
+var DefaultPatterns = []Pattern{
+	{Name: "email address", Expr: regexp.MustCompile(`(?i)\b[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}\b`)},
+	{Name: "phone number", Expr: regexp.MustCompile(`(?:\+?\d[\s\-.]?){9,14}\d`)},
+	{Name: "card number", Expr: regexp.MustCompile(`\b(?:\d[ \-]?){13,19}\b`), Severe: true, Valid: luhn},
+}
+
+// luhn reports whether the digits in s pass the Luhn checksum every payment
+// card number carries, so order numbers and the like are not taken for cards
+func luhn(s string) bool {
+	sum, digits := 0, 0
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits > 0 && sum%10 == 0
+}
+
+// RegexFilter flags messages matching any configured pattern.
+type RegexFilter struct {
+	patterns []Pattern
+}
+
+func NewRegexFilter(patterns []Pattern) *RegexFilter {
+	return &RegexFilter{patterns: patterns}
+}
+
+func (f *RegexFilter) Moderate(content string, settings Settings) Result {
+	result := Result{Verdict: Allow}
+	for _, p := range f.patterns {
+		if !p.matches(content) {
+			continue
+		}
+		if v := escalate(p.Severe, settings.Sensitivity); v > result.Verdict {
+			result = Result{Verdict: v, Filter: "regex", Reason: "Message contains a " + p.Name}
+		}
+	}
+	return result
+}
+
+func (p Pattern) matches(content string) bool {
+	if p.Valid == nil {
+		return p.Expr.MatchString(content)
+	}
+	for _, m := range p.Expr.FindAllString(content, -1) {
+		if p.Valid(m) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package moderation
+
+import "testing"
+
+func TestLuhn(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"4111111111111111", true},
+		{"4111 1111 1111 1111", true},
+		{"5500-0000-0000-0004", true},
+		{"4111111111111112", false},
+		{"1234567890123", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := luhn(tt.in); got != tt.want {
+			t.Errorf("luhn(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRegexFilterCardNumbers(t *testing.T) {
+	f := NewRegexFilter(DefaultPatterns)
+	settings := Settings{Sensitivity: SensitivityMedium}
+
+	if r := f.Moderate("my card is 4111 1111 1111 1111", settings); r.Reason != "Message contains a card number" {
+		t.Errorf("card number: %+v", r)
+	}
+	// Long numbers that fail the checksum are not cards
+	if r := f.Moderate("order 4111 1111 1111 1112 shipped", settings); r.Reason == "Message contains a card number" {
+		t.Errorf("order number taken for a card: %+v", r)
+	}
+	if r := f.Moderate("nothing to see here", settings); r.Verdict != Allow {
+		t.Errorf("plain text: %+v", r)
+	}
+}
@@ -0,0 +1,126 @@
+package moderation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Severity of a wordlist term.
+type Severity int
+
+const (
+	Mild Severity = iota
+	Severe
+)
+
+// DefaultWordlist is a deliberately small built-in list; deployments can
+// build their own WordlistFilter for anything more thorough.
+var DefaultWordlist = map[string]Severity{
+	"idiot":        Mild,
+	"stupid":       Mild,
+	"loser":        Mild,
+	"moron":        Mild,
+	"dumbass":      Mild,
+	"shit":         Mild,
+	"fuck":         Severe,
+	"bitch":        Severe,
+	"cunt":         Severe,
+	"retard":       Severe,
+	"kys":          Severe,
+	"killyourself": Severe,
+}
+
+// WordlistFilter flags messages containing listed terms.
+type WordlistFilter struct {
+	terms map[string]Severity
+}
+
+func NewWordlistFilter(terms map[string]Severity) *WordlistFilter {
+	normalised := make(map[string]Severity, len(terms))
+	for term, sev := range terms {
+		normalised[normalise(term)] = sev
+	}
+	return &WordlistFilter{terms: normalised}
+}
+
+func (f *WordlistFilter) Moderate(content string, settings Settings) Result {
+	hit, severe := "", false
+	words := Tokenize(content)
+	for i, w := range words {
+		sev, ok := f.terms[w]
+		if !ok && i+1 < len(words) {
+			// catch terms split in two, e.g. "kill yourself"
+			sev, ok = f.terms[w+words[i+1]]
+		}
+		if !ok {
+			continue
+		}
+		hit = w
+		if sev == Severe {
+			severe = true
+			break
+		}
+	}
+	if hit == "" {
+		return Result{Verdict: Allow}
+	}
+
+	return Result{
+		Verdict: escalate(severe, settings.Sensitivity),
+		Filter:  "wordlist",
+		Reason:  "Message contains blocked language",
+	}
+}
+
+// Tokenize lower-cases content, undoes common character substitutions and
+// splits it into words.
+func Tokenize(content string) []string {
+	return strings.FieldsFunc(normalise(content), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s",
+)
+
+func normalise(s string) string {
+	return leetReplacer.Replace(strings.ToLower(s))
+}
+
+// MatchKeyword returns the first keyword contained in content as a whole
+// word or phrase, using the same normalisation as the wordlist filter.
+func MatchKeyword(content string, keywords []string) (string, bool) {
+	haystack := " " + strings.Join(Tokenize(content), " ") + " "
+	for _, k := range keywords {
+		needle := strings.Join(Tokenize(k), " ")
+		if needle != "" && strings.Contains(haystack, " "+needle+" ") {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+// ContainsListed returns the first DefaultWordlist term spelled out by s as
+// a whole word, or by consecutive words run together ("kill-yourself",
+// "f-u-c-k"). It is meant for identifiers such as slugs. Terms inside longer
+// words do not count, so "scunthorpe" and "skyscraper" stay usable.
+func ContainsListed(s string) (string, bool) {
+	words := Tokenize(s)
+	for term := range DefaultWordlist {
+		needle := normalise(term)
+		for i := range words {
+			joined := ""
+			for _, w := range words[i:] {
+				joined += w
+				if joined == needle {
+					return term, true
+				}
+				if !strings.HasPrefix(needle, joined) {
+					break
+				}
+			}
+		}
+	}
+	return "", false
+}
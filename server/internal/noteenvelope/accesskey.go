@@ -0,0 +1,47 @@
+package noteenvelope
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Access keys gate the ciphertext of a note. Clients derive one from the note
+// password with the envelope's KDF and salt, then HKDF-SHA256 with info
+// "silentecho access key", so it reveals nothing about the encryption key.
+const (
+	minAccessKeySize = 16
+	maxAccessKeySize = 64
+)
+
+var ErrAccessKey = errors.New("access key must be 16 to 64 bytes of base64")
+
+// HashAccessKey returns the verifier stored for an access key. The key is
+// prehashed because bcrypt ignores input past 72 bytes.
+func HashAccessKey(key string) (string, error) {
+	raw, err := decode(key)
+	if err != nil || len(raw) < minAccessKeySize || len(raw) > maxAccessKeySize {
+		return "", ErrAccessKey
+	}
+	hash, err := bcrypt.GenerateFromPassword(prehash(raw), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckAccessKey reports whether key matches the verifier
+func CheckAccessKey(verifier, key string) bool {
+	raw, err := decode(key)
+	if err != nil {
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(verifier), prehash(raw)) == nil
+}
+
+func prehash(raw []byte) []byte {
+	sum := sha256.Sum256(raw)
+	return []byte(hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,155 @@
+package noteenvelope
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// Version 1 is AES-256-GCM with a 96-bit IV under a password derived key
+const CurrentVersion = 1
+
+// Key derivation functions clients may use. KDFNone is for random note keys
+// that are not derived from a password, e.g. ones wrapped to recipients.
+const (
+	KDFPBKDF2   = "pbkdf2-sha256"
+	KDFArgon2id = "argon2id"
+	KDFNone     = "none"
+)
+
+// Parameters of the original web client, which sent base64(salt|iv|ciphertext)
+const (
+	legacySaltSize   = 16
+	legacyIterations = 100000
+)
+
+const (
+	ivSize      = 12
+	minSaltSize = 16
+	maxSaltSize = 64
+	gcmTagSize  = 16
+)
+
+var (
+	ErrMalformed = errors.New("ciphertext envelope is malformed")
+	ErrTooLarge  = errors.New("ciphertext is too large")
+)
+
+// Envelope describes how a note was encrypted. Binary fields are base64.
+type Envelope struct {
+	Version     int    `json:"version"`
+	KDF         string `json:"kdf"`
+	Iterations  int    `json:"iterations"`
+	Memory      int    `json:"memory,omitempty"`      // argon2id, KiB
+	Parallelism int    `json:"parallelism,omitempty"` // argon2id
+	Salt        string `json:"salt"`
+	IV          string `json:"iv"`
+	Ciphertext  string `json:"ciphertext"`
+}
+
+// Validate checks structure and limits and returns the envelope with its
+// binary fields in canonical standard base64
+func Validate(e Envelope, maxCiphertext int) (Envelope, error) {
+	if e.Version != CurrentVersion {
+		return e, fmt.Errorf("unsupported envelope version %d", e.Version)
+	}
+
+	switch e.KDF {
+	case KDFPBKDF2:
+		if e.Iterations < legacyIterations || e.Iterations > 10000000 {
+			return e, fmt.Errorf("%s needs between %d and 10000000 iterations", KDFPBKDF2, legacyIterations)
+		}
+		if e.Memory != 0 || e.Parallelism != 0 {
+			return e, fmt.Errorf("%s takes no memory or parallelism", KDFPBKDF2)
+		}
+	case KDFArgon2id:
+		if e.Iterations < 1 || e.Iterations > 16 {
+			return e, fmt.Errorf("%s needs between 1 and 16 iterations", KDFArgon2id)
+		}
+		if e.Memory < 19*1024 || e.Memory > 1024*1024 {
+			return e, fmt.Errorf("%s memory must be between 19456 and 1048576 KiB", KDFArgon2id)
+		}
+		if e.Parallelism < 1 || e.Parallelism > 16 {
+			return e, fmt.Errorf("%s parallelism must be between 1 and 16", KDFArgon2id)
+		}
+	case KDFNone:
+		if e.Iterations != 0 || e.Memory != 0 || e.Parallelism != 0 || e.Salt != "" {
+			return e, fmt.Errorf("%s takes no parameters or salt", KDFNone)
+		}
+	default:
+		return e, fmt.Errorf("unknown kdf %q", e.KDF)
+	}
+
+	var salt []byte
+	if e.KDF != KDFNone {
+		var err error
+		salt, err = decode(e.Salt)
+		if err != nil || len(salt) < minSaltSize || len(salt) > maxSaltSize {
+			return e, fmt.Errorf("salt must be %d to %d bytes", minSaltSize, maxSaltSize)
+		}
+	}
+	iv, err := decode(e.IV)
+	if err != nil || len(iv) != ivSize {
+		return e, fmt.Errorf("iv must be %d bytes", ivSize)
+	}
+	if base64.StdEncoding.DecodedLen(len(e.Ciphertext)) > maxCiphertext+3 {
+		return e, ErrTooLarge
+	}
+	ct, err := decode(e.Ciphertext)
+	if err != nil || len(ct) <= gcmTagSize {
+		return e, ErrMalformed
+	}
+	if len(ct) > maxCiphertext {
+		return e, ErrTooLarge
+	}
+
+	if salt != nil {
+		e.Salt = base64.StdEncoding.EncodeToString(salt)
+	}
+	e.IV = base64.StdEncoding.EncodeToString(iv)
+	e.Ciphertext = base64.StdEncoding.EncodeToString(ct)
+	return e, nil
+}
+
+// ParseLegacy reads the original base64(salt|iv|ciphertext) format, which is
+// a version 1 envelope with PBKDF2 and fixed parameters
+func ParseLegacy(blob string, maxCiphertext int) (Envelope, error) {
+	raw, err := decode(blob)
+	if err != nil || len(raw) <= legacySaltSize+ivSize+gcmTagSize {
+		return Envelope{}, ErrMalformed
+	}
+	return Validate(Envelope{
+		Version:    CurrentVersion,
+		KDF:        KDFPBKDF2,
+		Iterations: legacyIterations,
+		Salt:       base64.StdEncoding.EncodeToString(raw[:legacySaltSize]),
+		IV:         base64.StdEncoding.EncodeToString(raw[legacySaltSize : legacySaltSize+ivSize]),
+		Ciphertext: base64.StdEncoding.EncodeToString(raw[legacySaltSize+ivSize:]),
+	}, maxCiphertext)
+}
+
+// Legacy packs the envelope in the original format for clients that predate
+// envelopes. ok is false when those clients could not decrypt it.
+func Legacy(e Envelope) (blob string, ok bool) {
+	if e.Version != CurrentVersion || e.KDF != KDFPBKDF2 || e.Iterations != legacyIterations {
+		return "", false
+	}
+	salt, err1 := decode(e.Salt)
+	iv, err2 := decode(e.IV)
+	ct, err3 := decode(e.Ciphertext)
+	if err1 != nil || err2 != nil || err3 != nil || len(salt) != legacySaltSize {
+		return "", false
+	}
+	raw := append(append(salt, iv...), ct...)
+	return base64.StdEncoding.EncodeToString(raw), true
+}
+
+// decode accepts standard and URL-safe base64, padded or not
+func decode(s string) ([]byte, error) {
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.RawStdEncoding, base64.URLEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, nil
+		}
+	}
+	return nil, ErrMalformed
+}
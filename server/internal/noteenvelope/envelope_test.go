@@ -0,0 +1,127 @@
+package noteenvelope
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func b64(n int) string {
+	return base64.StdEncoding.EncodeToString(make([]byte, n))
+}
+
+func TestValidate(t *testing.T) {
+	pbkdf2 := Envelope{Version: 1, KDF: KDFPBKDF2, Iterations: 600000, Salt: b64(16), IV: b64(12), Ciphertext: b64(32)}
+	argon := Envelope{Version: 1, KDF: KDFArgon2id, Iterations: 3, Memory: 64 * 1024, Parallelism: 1, Salt: b64(16), IV: b64(12), Ciphertext: b64(32)}
+	none := Envelope{Version: 1, KDF: KDFNone, IV: b64(12), Ciphertext: b64(32)}
+
+	tests := []struct {
+		name   string
+		modify func(e *Envelope)
+		base   Envelope
+		ok     bool
+	}{
+		{"pbkdf2", nil, pbkdf2, true},
+		{"argon2id", nil, argon, true},
+		{"none", nil, none, true},
+		{"unknown version", func(e *Envelope) { e.Version = 2 }, pbkdf2, false},
+		{"unknown kdf", func(e *Envelope) { e.KDF = "scrypt" }, pbkdf2, false},
+		{"few iterations", func(e *Envelope) { e.Iterations = 1000 }, pbkdf2, false},
+		{"pbkdf2 with memory", func(e *Envelope) { e.Memory = 1024 }, pbkdf2, false},
+		{"argon2id low memory", func(e *Envelope) { e.Memory = 1024 }, argon, false},
+		{"argon2id no parallelism", func(e *Envelope) { e.Parallelism = 0 }, argon, false},
+		{"none with salt", func(e *Envelope) { e.Salt = b64(16) }, none, false},
+		{"short salt", func(e *Envelope) { e.Salt = b64(8) }, pbkdf2, false},
+		{"short iv", func(e *Envelope) { e.IV = b64(8) }, pbkdf2, false},
+		{"tag only", func(e *Envelope) { e.Ciphertext = b64(16) }, pbkdf2, false},
+		{"too large", func(e *Envelope) { e.Ciphertext = b64(2048) }, pbkdf2, false},
+		{"not base64", func(e *Envelope) { e.Ciphertext = "!!!" }, pbkdf2, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.base
+			if tt.modify != nil {
+				tt.modify(&e)
+			}
+			_, err := Validate(e, 1024)
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate error = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
+
+func TestValidateCanonicalises(t *testing.T) {
+	raw := []byte{0xfb, 0xff, 0xfe, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09}
+	e := Envelope{
+		Version:    1,
+		KDF:        KDFNone,
+		IV:         base64.RawURLEncoding.EncodeToString(raw),
+		Ciphertext: base64.RawURLEncoding.EncodeToString(append(raw, raw...)),
+	}
+	got, err := Validate(e, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.IV != base64.StdEncoding.EncodeToString(raw) {
+		t.Errorf("IV = %q, want standard base64", got.IV)
+	}
+}
+
+func TestLegacyRoundTrip(t *testing.T) {
+	raw := make([]byte, legacySaltSize+ivSize+40)
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+	blob := base64.StdEncoding.EncodeToString(raw)
+
+	e, err := ParseLegacy(blob, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.KDF != KDFPBKDF2 || e.Iterations != legacyIterations {
+		t.Errorf("ParseLegacy = %+v", e)
+	}
+
+	back, ok := Legacy(e)
+	if !ok || back != blob {
+		t.Errorf("Legacy = %q, %v, want the original blob", back, ok)
+	}
+
+	// Clients that predate envelopes cannot read other parameters
+	e.Iterations = 600000
+	if _, ok := Legacy(e); ok {
+		t.Error("Legacy accepted non-legacy iterations")
+	}
+
+	if _, err := ParseLegacy(b64(legacySaltSize+ivSize+gcmTagSize), 1024); err != ErrMalformed {
+		t.Errorf("ParseLegacy of a blob without ciphertext: %v", err)
+	}
+}
+
+func TestAccessKey(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	other := base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+
+	verifier, err := HashAccessKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CheckAccessKey(verifier, key) {
+		t.Error("matching key rejected")
+	}
+	if CheckAccessKey(verifier, other) {
+		t.Error("other key accepted")
+	}
+	if CheckAccessKey(verifier, "not base64!") {
+		t.Error("malformed key accepted")
+	}
+
+	short := base64.StdEncoding.EncodeToString([]byte("short"))
+	long := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("x", maxAccessKeySize+1)))
+	for _, k := range []string{short, long, "!!!"} {
+		if _, err := HashAccessKey(k); err != ErrAccessKey {
+			t.Errorf("HashAccessKey(%q) = %v, want ErrAccessKey", k, err)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package notify
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification modes for new messages
+const (
+	ModeOff     = "off"
+	ModeInstant = "instant"
+	ModeHourly  = "hourly"
+	ModeDaily   = "daily"
+)
+
+// ValidMode reports whether mode is a known notification mode
+func ValidMode(mode string) bool {
+	switch mode {
+	case ModeOff, ModeInstant, ModeHourly, ModeDaily:
+		return true
+	}
+	return false
+}
+
+// DigestPeriod is how often digests of a mode are sent
+func DigestPeriod(mode string) time.Duration {
+	switch mode {
+	case ModeHourly:
+		return time.Hour
+	case ModeDaily:
+		return 24 * time.Hour
+	}
+	return 0
+}
+
+var (
+	ErrInvalidToken = errors.New("invalid unsubscribe token")
+	ErrExpiredToken = errors.New("unsubscribe token has expired")
+)
+
+// UnsubscribeToken signs a user ID and an expiry so links in emails can turn
+// off notifications without logging in
+func UnsubscribeToken(key string, userID uuid.UUID, expiresAt time.Time) string {
+	var payload [24]byte
+	copy(payload[:16], userID[:])
+	binary.BigEndian.PutUint64(payload[16:], uint64(expiresAt.Unix()))
+	id := base64.RawURLEncoding.EncodeToString(payload[:])
+	return id + "." + base64.RawURLEncoding.EncodeToString(sign(key, id))
+}
+
+// VerifyUnsubscribeToken returns the user ID an unsubscribe token was issued for
+func VerifyUnsubscribeToken(key, token string, now time.Time) (uuid.UUID, error) {
+	id, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return uuid.Nil, ErrInvalidToken
+	}
+	got, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(got, sign(key, id)) {
+		return uuid.Nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(id)
+	if err != nil || len(payload) != 24 {
+		return uuid.Nil, ErrInvalidToken
+	}
+	if now.Unix() > int64(binary.BigEndian.Uint64(payload[16:])) {
+		return uuid.Nil, ErrExpiredToken
+	}
+	return uuid.FromBytes(payload[:16])
+}
+
+func sign(key, id string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(id))
+	return mac.Sum(nil)
+}
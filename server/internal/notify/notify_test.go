@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	id := uuid.New()
+	now := time.Now()
+	token := UnsubscribeToken("key", id, now.Add(time.Hour))
+
+	got, err := VerifyUnsubscribeToken("key", token, now)
+	if err != nil || got != id {
+		t.Fatalf("Verify = %v, %v, want %v", got, err, id)
+	}
+
+	if _, err := VerifyUnsubscribeToken("key", token, now.Add(2*time.Hour)); err != ErrExpiredToken {
+		t.Errorf("after expiry: %v, want %v", err, ErrExpiredToken)
+	}
+	if _, err := VerifyUnsubscribeToken("other", token, now); err != ErrInvalidToken {
+		t.Errorf("other key: %v, want %v", err, ErrInvalidToken)
+	}
+
+	// Moving the expiry breaks the signature
+	later := UnsubscribeToken("key", id, now.Add(24*time.Hour))
+	_, sig, _ := strings.Cut(token, ".")
+	payload, _, _ := strings.Cut(later, ".")
+	if _, err := VerifyUnsubscribeToken("key", payload+"."+sig, now); err != ErrInvalidToken {
+		t.Errorf("extended expiry: %v, want %v", err, ErrInvalidToken)
+	}
+
+	if _, err := VerifyUnsubscribeToken("key", "no-signature", now); err != ErrInvalidToken {
+		t.Errorf("malformed: %v, want %v", err, ErrInvalidToken)
+	}
+}
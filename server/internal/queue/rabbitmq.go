@@ -1,6 +1,9 @@
 package queue
 
 import (
+	"fmt"
+	"time"
+
 	amqp "github.com/rabbitmq/amqp091-go"
 )
 
@@ -83,3 +86,158 @@ func (r *RabbitMQ) Close() {
 		r.Conn.Close()
 	}
 }
+
+func (r *RabbitMQ) DeclareExchange(name, kind string) error {
+	ch, err := r.NewChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	return ch.ExchangeDeclare(
+		name,
+		kind,
+		true,  // durable
+		false, // auto-delete
+		false, // internal
+		false, // no-wait
+		nil,
+	)
+}
+
+func (r *RabbitMQ) PublishExchange(exchange, routingKey string, body []byte) error {
+	ch, err := r.NewChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	return ch.Publish(
+		exchange,
+		routingKey,
+		false,
+		false,
+		amqp.Publishing{
+			ContentType: "application/json",
+			Body:        body,
+		},
+	)
+}
+
+// BindQueue routes messages published to exchange with matching keys into queue
+func (r *RabbitMQ) BindQueue(queue, exchange string, keys ...string) error {
+	ch, err := r.NewChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for _, key := range keys {
+		if err := ch.QueueBind(queue, key, exchange, false, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Subscribe consumes exchange through a private, auto-deleted queue, so every
+// subscriber (e.g. every server instance) gets its own copy of each message
+func (r *RabbitMQ) Subscribe(exchange string, keys ...string) (<-chan amqp.Delivery, *amqp.Channel, error) {
+	ch, err := r.NewChannel()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	q, err := ch.QueueDeclare(
+		"",
+		false, // durable
+		true,  // auto-delete
+		true,  // exclusive
+		false, // no-wait
+		nil,
+	)
+	if err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+
+	for _, key := range keys {
+		if err := ch.QueueBind(q.Name, key, exchange, false, nil); err != nil {
+			ch.Close()
+			return nil, nil, err
+		}
+	}
+
+	msgs, err := ch.Consume(
+		q.Name,
+		"",
+		true, // auto ack, live updates are not retried
+		true, // exclusive
+		false,
+		false,
+		nil,
+	)
+	if err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+
+	return msgs, ch, nil
+}
+
+// DelayQueueName names the queue under base that holds messages for delay
+func DelayQueueName(base string, delay time.Duration) string {
+	return fmt.Sprintf("%s.%d", base, delay.Milliseconds())
+}
+
+// DeclareDelayQueues declares one queue per delay under base, each moving its
+// messages to target once the queue's fixed TTL passes. RabbitMQ only expires
+// messages at the head of a queue, so sharing one queue between delays would
+// hold short retries back behind long ones.
+func (r *RabbitMQ) DeclareDelayQueues(base, target string, delays []time.Duration) error {
+	ch, err := r.NewChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	for _, delay := range delays {
+		_, err := ch.QueueDeclare(
+			DelayQueueName(base, delay),
+			true,  // durable
+			false, // auto-delete
+			false, // exclusive
+			false, // no-wait
+			amqp.Table{
+				"x-message-ttl":             delay.Milliseconds(),
+				"x-dead-letter-exchange":    "",
+				"x-dead-letter-routing-key": target,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PublishDelayed publishes body to the delay queue of base for delay, which
+// must be one of the delays passed to DeclareDelayQueues
+func (r *RabbitMQ) PublishDelayed(base string, body []byte, delay time.Duration) error {
+	ch, err := r.NewChannel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+
+	return ch.Publish(
+		"",
+		DelayQueueName(base, delay),
+		false,
+		false,
+		amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Body:         body,
+		},
+	)
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"github.com/rohits-web03/SilentEcho/server/internal/blobstore"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+)
+
+// OpenBlobStore opens the blob store for note attachments selected in config
+func OpenBlobStore() (blobstore.Store, error) {
+	return blobstore.New(blobstore.Config{
+		Backend: config.Envs.BlobStore,
+		Dir:     config.Envs.BlobDir,
+		S3: blobstore.S3Config{
+			Endpoint:  config.Envs.S3Endpoint,
+			Region:    config.Envs.S3Region,
+			Bucket:    config.Envs.S3Bucket,
+			AccessKey: config.Envs.S3AccessKey,
+			SecretKey: config.Envs.S3SecretKey,
+			PathStyle: config.Envs.S3PathStyle,
+		},
+	})
+}
@@ -13,20 +13,46 @@ var DB *gorm.DB
 
 func ConnectDatabase() {
 	dsn := config.Envs.DB_URL
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{TranslateError: true})
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
 	}
 	// Run migrations
 	err = db.AutoMigrate(
 		&models.User{},
+		&models.NoteFolder{},
 		&models.Note{},
+		&models.NoteLabel{},
+		&models.NoteRecipient{},
+		&models.Prompt{},
 		&models.Message{},
+		&models.BlockedWord{},
+		&models.BlockedSender{},
+		&models.SpentChallenge{},
+		&models.Reply{},
+		&models.DataKey{},
+		&models.AcceptSchedule{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Integration{},
+		&models.PushSubscription{},
+		&models.NoteView{},
+		&models.Attachment{},
+		&models.AttachmentChunk{},
 		// add more models here
 	)
 	if err != nil {
 		log.Fatal("Migration failed:", err)
 	}
 	DB = db
+
+	keyring, err := LoadKeyring()
+	if err != nil {
+		log.Fatal("Invalid master keys:", err)
+	}
+	if keyring.Enabled() {
+		models.MessageSealer = newMessageSealer(keyring)
+		log.Printf("Encryption at rest enabled with master key %s\n", keyring.CurrentID())
+	}
 	log.Println("Successfully connected to database")
 }
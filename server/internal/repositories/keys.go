@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/envelope"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoadKeyring builds the master keyring from config
+func LoadKeyring() (*envelope.Keyring, error) {
+	return envelope.ParseKeyring(config.Envs.MasterKeys, config.Envs.MasterKeyID)
+}
+
+// messageSealer encrypts message content with per-user data keys. Unwrapped
+// data keys are cached, rotation only re-wraps them so the cache stays valid.
+type messageSealer struct {
+	keyring *envelope.Keyring
+	keys    sync.Map // uuid.UUID -> []byte
+}
+
+func newMessageSealer(keyring *envelope.Keyring) *messageSealer {
+	return &messageSealer{keyring: keyring}
+}
+
+func (s *messageSealer) Seal(tx *gorm.DB, userID uuid.UUID, plaintext string) (string, error) {
+	dek, err := s.dataKey(tx, userID, true)
+	if err != nil {
+		return "", err
+	}
+	return envelope.Seal(dek, plaintext, userID[:])
+}
+
+func (s *messageSealer) Open(tx *gorm.DB, userID uuid.UUID, stored string) (string, error) {
+	if !envelope.IsSealed(stored) {
+		return stored, nil
+	}
+	dek, err := s.dataKey(tx, userID, false)
+	if err != nil {
+		return "", err
+	}
+	return envelope.Open(dek, stored, userID[:])
+}
+
+func (s *messageSealer) dataKey(tx *gorm.DB, userID uuid.UUID, create bool) ([]byte, error) {
+	if dek, ok := s.keys.Load(userID); ok {
+		return dek.([]byte), nil
+	}
+
+	db := tx.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	var record models.DataKey
+	err := db.Where("user_id = ?", userID).First(&record).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) && create {
+		_, wrapped, genErr := s.keyring.NewDataKey()
+		if genErr != nil {
+			return nil, genErr
+		}
+		record = models.DataKey{UserID: userID, WrappedKey: wrapped, MasterKeyID: s.keyring.CurrentID()}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&record).Error; err != nil {
+			return nil, err
+		}
+		// Another writer may have won the race, use whichever key was stored
+		err = db.Where("user_id = ?", userID).First(&record).Error
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dek, err := s.keyring.Unwrap(record.WrappedKey, record.MasterKeyID)
+	if err != nil {
+		return nil, err
+	}
+	s.keys.Store(userID, dek)
+	return dek, nil
+}
+
+// RotateDataKeys re-wraps every data key not wrapped by the current master
+// key. Message rows are untouched since the data keys themselves stay the same.
+func RotateDataKeys(keyring *envelope.Keyring) (int, error) {
+	var rotated int
+	var records []models.DataKey
+	err := DB.Where("master_key_id <> ?", keyring.CurrentID()).
+		FindInBatches(&records, 200, func(tx *gorm.DB, batch int) error {
+			for _, record := range records {
+				dek, err := keyring.Unwrap(record.WrappedKey, record.MasterKeyID)
+				if err != nil {
+					return err
+				}
+				wrapped, err := keyring.Wrap(dek)
+				if err != nil {
+					return err
+				}
+				err = DB.Model(&models.DataKey{}).
+					Where("user_id = ? AND master_key_id = ?", record.UserID, record.MasterKeyID).
+					Updates(map[string]interface{}{"wrapped_key": wrapped, "master_key_id": keyring.CurrentID()}).Error
+				if err != nil {
+					return err
+				}
+				rotated++
+			}
+			return nil
+		}).Error
+	return rotated, err
+}
+
+// SealPlaintextMessages encrypts messages and replies stored before
+// encryption at rest was enabled.
+func SealPlaintextMessages() (int, error) {
+	sealer, ok := models.MessageSealer.(*messageSealer)
+	if !ok {
+		return 0, errors.New("encryption at rest is not enabled")
+	}
+
+	var sealed int
+	var messages []models.Message
+	err := DB.Session(&gorm.Session{SkipHooks: true}).
+		Select("id", "user_id", "content").
+		Where("is_encrypted = ? AND content NOT LIKE ?", false, envelope.SealedPrefix+"%").
+		FindInBatches(&messages, 200, func(tx *gorm.DB, batch int) error {
+			for _, m := range messages {
+				content, err := sealer.Seal(DB, m.UserID, m.Content)
+				if err != nil {
+					return err
+				}
+				if err := DB.Model(&m).UpdateColumn("content", content).Error; err != nil {
+					return err
+				}
+				sealed++
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return sealed, err
+	}
+
+	var replies []models.Reply
+	err = DB.Session(&gorm.Session{SkipHooks: true}).
+		Select("id", "user_id", "content").
+		Where("is_encrypted = ? AND content NOT LIKE ?", false, envelope.SealedPrefix+"%").
+		FindInBatches(&replies, 200, func(tx *gorm.DB, batch int) error {
+			for _, r := range replies {
+				content, err := sealer.Seal(DB, r.UserID, r.Content)
+				if err != nil {
+					return err
+				}
+				if err := DB.Model(&r).UpdateColumn("content", content).Error; err != nil {
+					return err
+				}
+				sealed++
+			}
+			return nil
+		}).Error
+	return sealed, err
+}
@@ -0,0 +1,144 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"time"
+	_ "time/tzdata" // timezones must resolve in slim containers too
+
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+)
+
+// Status is the evaluated state of an accept schedule at a point in time.
+type Status struct {
+	Open       bool       `json:"open"`
+	Reason     string     `json:"reason,omitempty"`
+	NextOpenAt *time.Time `json:"nextOpenAt,omitempty"`
+}
+
+// Validate checks the timezone, windows and limits of a schedule.
+func Validate(s models.AcceptSchedule) error {
+	if _, err := time.LoadLocation(s.Timezone); err != nil {
+		return fmt.Errorf("unknown timezone %q", s.Timezone)
+	}
+	if s.MaxMessages != nil && *s.MaxMessages < 1 {
+		return errors.New("maxMessages must be at least 1")
+	}
+	for i, w := range s.Windows {
+		if len(w.Days) == 0 {
+			return fmt.Errorf("window %d has no days", i+1)
+		}
+		for _, d := range w.Days {
+			if d < time.Sunday || d > time.Saturday {
+				return fmt.Errorf("window %d has an invalid day %d", i+1, d)
+			}
+		}
+		if _, err := parseClock(w.Start); err != nil {
+			return fmt.Errorf("window %d start: %v", i+1, err)
+		}
+		if _, err := parseClock(w.End); err != nil {
+			return fmt.Errorf("window %d end: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate reports whether the schedule accepts messages at now, given how
+// many messages arrived since the schedule's CountFrom.
+func Evaluate(s models.AcceptSchedule, now time.Time, received int64) Status {
+	if s.CloseAt != nil && !now.Before(*s.CloseAt) {
+		return Status{Reason: "closed since " + s.CloseAt.UTC().Format(time.RFC3339)}
+	}
+	if s.MaxMessages != nil && received >= int64(*s.MaxMessages) {
+		return Status{Reason: fmt.Sprintf("message limit of %d reached", *s.MaxMessages)}
+	}
+	if len(s.Windows) == 0 {
+		return Status{Open: true}
+	}
+
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+
+	// Check today's windows and yesterday's overnight ones
+	for _, offset := range []int{0, -1} {
+		day := startOfDay(local).AddDate(0, 0, offset)
+		for _, w := range s.Windows {
+			start, end, ok := windowOn(w, day)
+			if ok && !local.Before(start) && local.Before(end) {
+				return Status{Open: true}
+			}
+		}
+	}
+
+	status := Status{Reason: "outside scheduled hours"}
+	if next, ok := nextOpening(s.Windows, local); ok && (s.CloseAt == nil || next.Before(*s.CloseAt)) {
+		status.NextOpenAt = &next
+	}
+	return status
+}
+
+// nextOpening finds the earliest window start after local within a week
+func nextOpening(windows []models.AcceptWindow, local time.Time) (time.Time, bool) {
+	var best time.Time
+	found := false
+	for offset := 0; offset <= 7; offset++ {
+		day := startOfDay(local).AddDate(0, 0, offset)
+		for _, w := range windows {
+			start, _, ok := windowOn(w, day)
+			if ok && start.After(local) && (!found || start.Before(best)) {
+				best, found = start, true
+			}
+		}
+		if found {
+			return best, true
+		}
+	}
+	return best, false
+}
+
+// windowOn returns the window's span if it starts on day
+func windowOn(w models.AcceptWindow, day time.Time) (time.Time, time.Time, bool) {
+	if !hasDay(w.Days, day.Weekday()) {
+		return time.Time{}, time.Time{}, false
+	}
+	startMin, err1 := parseClock(w.Start)
+	endMin, err2 := parseClock(w.End)
+	if err1 != nil || err2 != nil {
+		return time.Time{}, time.Time{}, false
+	}
+
+	start := atMinute(day, startMin)
+	end := atMinute(day, endMin)
+	if endMin <= startMin {
+		end = atMinute(day.AddDate(0, 0, 1), endMin)
+	}
+	return start, end, true
+}
+
+func hasDay(days []time.Weekday, d time.Weekday) bool {
+	for _, day := range days {
+		if day == d {
+			return true
+		}
+	}
+	return false
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time %q must be HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func atMinute(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
@@ -0,0 +1,129 @@
+package slugs
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
+)
+
+// Styles of generated slugs
+const (
+	StyleBase62 = "base62"
+	StyleWords  = "words"
+	StyleUUID   = "uuid"
+)
+
+// Limits of owner-chosen slugs
+const (
+	MinCustomLength = 4
+	MaxCustomLength = 64
+)
+
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var (
+	ErrCustomFormat = fmt.Errorf("slug must be %d to %d lowercase letters, digits or single hyphens, starting and ending with a letter or digit", MinCustomLength, MaxCustomLength)
+	ErrReserved     = errors.New("slug is reserved")
+	ErrOffensive    = errors.New("slug contains blocked language")
+)
+
+var customPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// reserved are path segments under /api/notes and names that would pass for
+// the service itself
+var reserved = map[string]bool{
+	"anonymous": true, "user": true, "users": true, "unlock": true,
+	"manage": true, "views": true, "attachments": true, "api": true,
+	"admin": true, "administrator": true, "root": true, "system": true,
+	"support": true, "help": true, "security": true, "official": true,
+	"silentecho": true, "login": true, "logout": true, "signup": true,
+	"register": true, "settings": true, "account": true, "new": true,
+	"notes": true, "note": true, "null": true, "undefined": true,
+	"folders": true, "labels": true, "shared": true, "recipients": true,
+}
+
+// Generator produces slugs in one of the styles
+type Generator struct {
+	Style  string
+	Length int // characters of a base62 slug
+	Words  int // words of a passphrase slug
+}
+
+// New returns a fresh random slug
+func (g Generator) New() (string, error) {
+	switch g.Style {
+	case StyleBase62:
+		return Base62(g.Length)
+	case StyleWords:
+		return Passphrase(g.Words)
+	case StyleUUID:
+		return uuid.NewString(), nil
+	}
+	return "", fmt.Errorf("unknown slug style %q", g.Style)
+}
+
+// ValidStyle reports whether style names a generator
+func ValidStyle(style string) bool {
+	return style == StyleBase62 || style == StyleWords || style == StyleUUID
+}
+
+// Base62 returns n uniformly random characters from [0-9A-Za-z]
+func Base62(n int) (string, error) {
+	if n < 1 {
+		return "", errors.New("base62 slug length must be positive")
+	}
+	b := make([]byte, n)
+	for i := range b {
+		j, err := randIndex(len(base62))
+		if err != nil {
+			return "", err
+		}
+		b[i] = base62[j]
+	}
+	return string(b), nil
+}
+
+// Passphrase returns n random words joined by hyphens, e.g. "otter-maple-comet"
+func Passphrase(n int) (string, error) {
+	if n < 1 {
+		return "", errors.New("passphrase slug needs at least one word")
+	}
+	words := make([]string, n)
+	for i := range words {
+		j, err := randIndex(len(wordlist))
+		if err != nil {
+			return "", err
+		}
+		words[i] = wordlist[j]
+	}
+	return strings.Join(words, "-"), nil
+}
+
+// CheckCustom validates an owner-chosen slug. Anyone can guess these, so
+// they only make a note easier to share, not harder to find.
+func CheckCustom(slug string) error {
+	if len(slug) < MinCustomLength || len(slug) > MaxCustomLength || !customPattern.MatchString(slug) {
+		return ErrCustomFormat
+	}
+	if reserved[slug] || reserved[strings.ReplaceAll(slug, "-", "")] {
+		return ErrReserved
+	}
+	if _, ok := moderation.ContainsListed(slug); ok {
+		return ErrOffensive
+	}
+	return nil
+}
+
+func randIndex(n int) (int, error) {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(i.Int64()), nil
+}
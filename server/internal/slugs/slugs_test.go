@@ -0,0 +1,59 @@
+package slugs
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckCustom(t *testing.T) {
+	tests := []struct {
+		slug string
+		want error
+	}{
+		{"team-offsite-2025", nil},
+		{"abcd", nil},
+		{"skyscraper", nil},
+		{"scunthorpe-fc", nil},
+		{"classic-cocktails", nil},
+		{strings.Repeat("a", MaxCustomLength), nil},
+		{"abc", ErrCustomFormat},
+		{strings.Repeat("a", MaxCustomLength+1), ErrCustomFormat},
+		{"Upper-Case", ErrCustomFormat},
+		{"-leading", ErrCustomFormat},
+		{"trailing-", ErrCustomFormat},
+		{"double--hyphen", ErrCustomFormat},
+		{"under_score", ErrCustomFormat},
+		{"notes", ErrReserved},
+		{"shared", ErrReserved},
+		{"silent-echo", ErrReserved},
+		{"you-idiot", ErrOffensive},
+		{"st00pid-idi0t", ErrOffensive},
+		{"kys-now", ErrOffensive},
+		{"kill-yourself", ErrOffensive},
+		{"f-u-c-k", ErrOffensive},
+	}
+	for _, tt := range tests {
+		if got := CheckCustom(tt.slug); got != tt.want {
+			t.Errorf("CheckCustom(%q) = %v, want %v", tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestGenerator(t *testing.T) {
+	s, err := Generator{Style: StyleBase62, Length: 12}.New()
+	if err != nil || len(s) != 12 || strings.Trim(s, base62) != "" {
+		t.Errorf("base62 slug %q, %v", s, err)
+	}
+
+	s, err = Generator{Style: StyleWords, Words: 4}.New()
+	if err != nil || len(strings.Split(s, "-")) != 4 {
+		t.Errorf("passphrase slug %q, %v", s, err)
+	}
+
+	if _, err := (Generator{Style: "emoji"}).New(); err == nil {
+		t.Error("unknown style accepted")
+	}
+	if _, err := Base62(0); err == nil {
+		t.Error("empty base62 slug accepted")
+	}
+}
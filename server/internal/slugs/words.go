@@ -0,0 +1,40 @@
+package slugs
+
+// wordlist holds 256 short, easily spelled words, so each one adds 8 bits
+// to a passphrase slug
+var wordlist = [...]string{
+	"acorn", "agent", "alarm", "album", "alpha", "amber", "anchor", "angle",
+	"anvil", "apple", "apron", "arrow", "aspen", "atlas", "attic", "autumn",
+	"badge", "bagel", "baker", "bamboo", "banjo", "barley", "barrel", "basil",
+	"basket", "beacon", "beaver", "beetle", "berry", "bison", "blanket",
+	"blossom", "bonnet", "border", "bottle", "branch", "bread", "breeze",
+	"brick", "bridge", "brook", "bucket", "bugle", "butter", "cabin", "cactus",
+	"camel", "candle", "canoe", "canyon", "carbon", "carpet", "carrot",
+	"castle", "cedar", "cello", "chalk", "cherry", "chess", "cider", "circle",
+	"citrus", "clover", "cobalt", "cobble", "cocoa", "comet", "copper", "coral",
+	"cotton", "cradle", "crane", "crayon", "cricket", "crystal", "cypress",
+	"daisy", "dancer", "delta", "desert", "dolphin", "domino", "dragon", "drum",
+	"dune", "eagle", "easel", "echo", "elbow", "ember", "engine", "falcon",
+	"feather", "fennel", "fern", "fiddle", "finch", "fjord", "flame", "flint",
+	"forest", "fossil", "fountain", "fox", "galaxy", "garden", "garlic",
+	"geyser", "ginger", "glacier", "globe", "goose", "granite", "grape",
+	"gravel", "hammock", "harbor", "harvest", "hazel", "helmet", "heron",
+	"hickory", "honey", "horizon", "iris", "island", "ivory", "jacket", "jade",
+	"jasmine", "jelly", "jigsaw", "jungle", "kayak", "kernel", "kettle", "kiwi",
+	"koala", "ladder", "lagoon", "lantern", "lark", "laurel", "lemon", "lily",
+	"linen", "lizard", "lobster", "locket", "lotus", "magnet", "mango", "maple",
+	"marble", "meadow", "melon", "meteor", "mint", "mirror", "mitten",
+	"monsoon", "mosaic", "moss", "muffin", "mural", "nectar", "needle",
+	"nickel", "noodle", "nutmeg", "oasis", "ocean", "olive", "onion", "opal",
+	"orbit", "orchid", "otter", "oyster", "paddle", "palm", "panda", "paper",
+	"parrot", "pasta", "peach", "pearl", "pebble", "pepper", "piano", "pillow",
+	"pine", "planet", "plum", "pocket", "pollen", "pony", "poppy", "prism",
+	"pumpkin", "puzzle", "quartz", "quill", "rabbit", "radar", "radish",
+	"raven", "reef", "ribbon", "river", "robin", "rocket", "saddle", "saffron",
+	"salmon", "sandal", "satin", "sequoia", "shadow", "shell", "silver",
+	"sketch", "sparrow", "spruce", "squash", "starling", "stone", "sugar",
+	"summit", "sunset", "swan", "tablet", "tango", "tapir", "teapot", "thistle",
+	"thunder", "tiger", "timber", "tomato", "topaz", "tulip", "tundra",
+	"turnip", "velvet", "violet", "walnut", "walrus", "willow", "window",
+	"winter", "wizard", "yarrow", "zebra", "zephyr", "zinnia",
+}
@@ -0,0 +1,58 @@
+package spam
+
+import (
+	"hash/fnv"
+	"math/bits"
+	"strings"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
+)
+
+const shingleSize = 3
+
+// SimHash computes a 64-bit locality sensitive hash over word shingles, so
+// near-duplicate messages end up a small Hamming distance apart.
+func SimHash(content string) uint64 {
+	words := moderation.Tokenize(content)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var weights [64]int
+	for _, shingle := range shingles(words) {
+		h := fnv.New64a()
+		h.Write([]byte(shingle))
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<uint(i)) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << uint(i)
+		}
+	}
+	return hash
+}
+
+// Distance is the number of differing bits between two hashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+func shingles(words []string) []string {
+	if len(words) <= shingleSize {
+		return []string{strings.Join(words, " ")}
+	}
+	out := make([]string, 0, len(words)-shingleSize+1)
+	for i := 0; i+shingleSize <= len(words); i++ {
+		out = append(out, strings.Join(words[i:i+shingleSize], " "))
+	}
+	return out
+}
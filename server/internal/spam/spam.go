@@ -0,0 +1,144 @@
+package spam
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/moderation"
+)
+
+// Recent is the part of an earlier message to the same recipient the scorer needs.
+type Recent struct {
+	SimHash   uint64
+	CreatedAt time.Time
+	// Opaque messages were stored as ciphertext and have no SimHash, they
+	// only count towards bursts
+	Opaque bool
+}
+
+// Options tune the scorer; zero values fall back to the defaults below.
+type Options struct {
+	// Hamming distance under which two messages count as near-duplicates
+	MaxDistance int
+	// Messages to one recipient within BurstWindow before it counts as a flood
+	BurstLimit  int
+	BurstWindow time.Duration
+	// Links allowed before a body counts as link-heavy
+	MaxLinks int
+}
+
+var DefaultOptions = Options{
+	MaxDistance: 3,
+	BurstLimit:  10,
+	BurstWindow: time.Minute,
+	MaxLinks:    2,
+}
+
+// Signal is one reason a message scored points.
+type Signal struct {
+	Name   string  `json:"name"`
+	Points float64 `json:"points"`
+}
+
+// Score is the outcome of scoring a single message.
+type Score struct {
+	SimHash uint64
+	Total   float64
+	Signals []Signal
+}
+
+// Reason summarises the signals for storage alongside a quarantined message.
+func (s Score) Reason() string {
+	names := make([]string, len(s.Signals))
+	for i, sig := range s.Signals {
+		names[i] = sig.Name
+	}
+	return fmt.Sprintf("Spam score %.1f (%s)", s.Total, strings.Join(names, ", "))
+}
+
+type Scorer struct {
+	opts Options
+}
+
+func NewScorer(opts Options) *Scorer {
+	if opts.MaxDistance <= 0 {
+		opts.MaxDistance = DefaultOptions.MaxDistance
+	}
+	if opts.BurstLimit <= 0 {
+		opts.BurstLimit = DefaultOptions.BurstLimit
+	}
+	if opts.BurstWindow <= 0 {
+		opts.BurstWindow = DefaultOptions.BurstWindow
+	}
+	if opts.MaxLinks <= 0 {
+		opts.MaxLinks = DefaultOptions.MaxLinks
+	}
+	return &Scorer{opts: opts}
+}
+
+// Score rates content against the recipient's recent messages. Higher is spammier.
+func (s *Scorer) Score(content string, recent []Recent, now time.Time) Score {
+	score := Score{SimHash: SimHash(content)}
+	words := moderation.Tokenize(content)
+
+	// Very short messages collide too easily, only exact repeats count for them
+	maxDistance := s.opts.MaxDistance
+	if len(words) < 4 {
+		maxDistance = 0
+	}
+
+	// Content without words hashes to 0, as do messages stored without a
+	// hash, so neither says anything about duplicates
+	duplicates := 0
+	if len(words) > 0 && score.SimHash != 0 {
+		for _, r := range recent {
+			if r.Opaque || r.SimHash == 0 {
+				continue
+			}
+			if Distance(score.SimHash, r.SimHash) <= maxDistance {
+				duplicates++
+			}
+		}
+	}
+
+	if duplicates > 0 {
+		score.add("duplicate content", min(2*float64(duplicates), 10))
+	}
+	s.scoreBurst(&score, recent, now)
+
+	links := moderation.CountLinks(content)
+	if links > s.opts.MaxLinks {
+		score.add("too many links", 3)
+	}
+	if links > 0 && float64(links)/float64(max(len(strings.Fields(content)), 1)) > 0.3 {
+		score.add("link-heavy body", 2)
+	}
+
+	return score
+}
+
+func (s *Score) add(name string, points float64) {
+	s.Signals = append(s.Signals, Signal{Name: name, Points: points})
+	s.Total += points
+}
+
+// ScoreOpaque rates content the server cannot read, such as ciphertext,
+// so only volume based signals apply.
+func (s *Scorer) ScoreOpaque(recent []Recent, now time.Time) Score {
+	var score Score
+	s.scoreBurst(&score, recent, now)
+	return score
+}
+
+func (s *Scorer) scoreBurst(score *Score, recent []Recent, now time.Time) {
+	burst := 0
+	for _, r := range recent {
+		if now.Sub(r.CreatedAt) <= s.opts.BurstWindow {
+			burst++
+		}
+	}
+	if over := burst - s.opts.BurstLimit; over >= 0 {
+		score.add("message burst", min(float64(over+1), 5))
+	}
+}
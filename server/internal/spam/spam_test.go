@@ -0,0 +1,76 @@
+package spam
+
+import (
+	"testing"
+	"time"
+)
+
+func hasSignal(s Score, name string) bool {
+	for _, sig := range s.Signals {
+		if sig.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestScoreDuplicates(t *testing.T) {
+	scorer := NewScorer(DefaultOptions)
+	now := time.Now()
+	text := "hey there, are you coming to the party on saturday night"
+	old := now.Add(-30 * time.Minute)
+
+	tests := []struct {
+		name    string
+		content string
+		recent  []Recent
+		want    bool
+	}{
+		{"repeat", text, []Recent{{SimHash: SimHash(text), CreatedAt: old}}, true},
+		{"near repeat", text + "!", []Recent{{SimHash: SimHash(text), CreatedAt: old}}, true},
+		{"different", "what did you think of the new album, honestly", []Recent{{SimHash: SimHash(text), CreatedAt: old}}, false},
+		{"short repeat", "hi", []Recent{{SimHash: SimHash("hi"), CreatedAt: old}}, true},
+		// Content without words and stored ciphertext both hash to 0
+		{"no words", "!!! ???", []Recent{{SimHash: 0, CreatedAt: old}}, false},
+		{"against ciphertext", "hi", []Recent{{SimHash: 0, CreatedAt: old, Opaque: true}}, false},
+		{"against opaque", text, []Recent{{SimHash: SimHash(text), CreatedAt: old, Opaque: true}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := scorer.Score(tt.content, tt.recent, now)
+			if hasSignal(got, "duplicate content") != tt.want {
+				t.Errorf("Score(%q) signals %v, want duplicate %v", tt.content, got.Signals, tt.want)
+			}
+		})
+	}
+}
+
+func TestScoreBurst(t *testing.T) {
+	scorer := NewScorer(Options{BurstLimit: 3, BurstWindow: time.Minute})
+	now := time.Now()
+
+	var recent []Recent
+	for i := 0; i < 3; i++ {
+		// Opaque rows count towards bursts even though they have no hash
+		recent = append(recent, Recent{CreatedAt: now.Add(-10 * time.Second), Opaque: true})
+	}
+	recent = append(recent, Recent{CreatedAt: now.Add(-time.Hour)})
+
+	if got := scorer.ScoreOpaque(recent, now); !hasSignal(got, "message burst") || got.Total != 1 {
+		t.Errorf("ScoreOpaque = %+v, want one burst point", got)
+	}
+	if got := scorer.ScoreOpaque(recent[:2], now); got.Total != 0 {
+		t.Errorf("ScoreOpaque under the limit = %+v", got)
+	}
+}
+
+func TestScoreLinks(t *testing.T) {
+	scorer := NewScorer(DefaultOptions)
+	got := scorer.Score("deals https://a.example https://b.example https://c.example", nil, time.Now())
+	if !hasSignal(got, "too many links") || !hasSignal(got, "link-heavy body") {
+		t.Errorf("Score signals %v, want link signals", got.Signals)
+	}
+	if got := scorer.Score("see https://a.example for the details of the trip next week", nil, time.Now()); got.Total != 0 {
+		t.Errorf("one link in a sentence scored %+v", got)
+	}
+}
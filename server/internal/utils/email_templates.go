@@ -8,3 +8,28 @@ func VerificationEmail(username, code string) (subject, plain, html string) {
 	html = fmt.Sprintf("<h2>Hello %s,</h2><p>Your verification code is: <b>%s</b></p>", username, code)
 	return
 }
+
+func NewMessageEmail(username, dashboardURL, unsubscribeURL string) (subject, plain, html string) {
+	subject = "You have a new anonymous message"
+	plain = fmt.Sprintf("Hello %s,\n\nSomeone sent you a new anonymous message on SilentEcho.\nRead it here: %s\n\nTo stop these emails, visit: %s\n", username, dashboardURL, unsubscribeURL)
+	html = fmt.Sprintf("<h2>Hello %s,</h2><p>Someone sent you a new anonymous message on SilentEcho.</p><p><a href=\"%s\">Read it on your dashboard</a></p><p><small><a href=\"%s\">Unsubscribe</a></small></p>", username, dashboardURL, unsubscribeURL)
+	return
+}
+
+func DigestEmail(username string, count int64, period, dashboardURL, unsubscribeURL string) (subject, plain, html string) {
+	noun := "messages"
+	if count == 1 {
+		noun = "message"
+	}
+	subject = fmt.Sprintf("Your %s SilentEcho digest: %d new %s", period, count, noun)
+	plain = fmt.Sprintf("Hello %s,\n\nYou received %d new anonymous %s since your last digest.\nRead them here: %s\n\nTo stop these emails, visit: %s\n", username, count, noun, dashboardURL, unsubscribeURL)
+	html = fmt.Sprintf("<h2>Hello %s,</h2><p>You received <b>%d</b> new anonymous %s since your last digest.</p><p><a href=\"%s\">Read them on your dashboard</a></p><p><small><a href=\"%s\">Unsubscribe</a></small></p>", username, count, noun, dashboardURL, unsubscribeURL)
+	return
+}
+
+func NoteViewedEmail(username, viewedAt, notesURL string) (subject, plain, html string) {
+	subject = "Your note was opened"
+	plain = fmt.Sprintf("Hello %s,\n\nA note you shared on SilentEcho was opened for the first time at %s.\nSee who viewed it here: %s\n", username, viewedAt, notesURL)
+	html = fmt.Sprintf("<h2>Hello %s,</h2><p>A note you shared on SilentEcho was opened for the first time at <b>%s</b>.</p><p><a href=\"%s\">See its access log</a></p>", username, viewedAt, notesURL)
+	return
+}
@@ -0,0 +1,32 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// SenderFingerprint derives an anonymised identifier for a message sender.
+// The recipient ID is mixed in so the same sender cannot be correlated
+// across inboxes, and the salt keeps the raw IP from being brute-forced.
+func SenderFingerprint(salt, recipientID, ip, userAgent string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(recipientID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(ip))
+	mac.Write([]byte{0})
+	mac.Write([]byte(userAgent))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ViewerFingerprint identifies repeat readers of one note. It is scoped to
+// the note, so readers cannot be followed from note to note.
+func ViewerFingerprint(salt, noteID, ip string) string {
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte("note-view"))
+	mac.Write([]byte{0})
+	mac.Write([]byte(noteID))
+	mac.Write([]byte{0})
+	mac.Write([]byte(ip))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
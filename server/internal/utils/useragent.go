@@ -0,0 +1,53 @@
+package utils
+
+import "strings"
+
+// UserAgentFamily reduces a User-Agent header to a coarse "Browser on OS"
+// label, enough to tell devices apart without storing the full string
+func UserAgentFamily(ua string) string {
+	lower := strings.ToLower(ua)
+	if lower == "" {
+		return "Unknown"
+	}
+
+	// Order matters, most browsers also claim to be Chrome or Safari
+	browser := "Other"
+	switch {
+	case strings.Contains(lower, "bot"), strings.Contains(lower, "crawler"), strings.Contains(lower, "spider"),
+		strings.Contains(lower, "preview"), strings.Contains(lower, "curl/"), strings.Contains(lower, "wget/"):
+		return "Bot or link preview"
+	case strings.Contains(lower, "edg/"), strings.Contains(lower, "edga/"), strings.Contains(lower, "edgios/"):
+		browser = "Edge"
+	case strings.Contains(lower, "opr/"), strings.Contains(lower, "opera"):
+		browser = "Opera"
+	case strings.Contains(lower, "samsungbrowser/"):
+		browser = "Samsung Internet"
+	case strings.Contains(lower, "firefox/"), strings.Contains(lower, "fxios/"):
+		browser = "Firefox"
+	case strings.Contains(lower, "chrome/"), strings.Contains(lower, "crios/"):
+		browser = "Chrome"
+	case strings.Contains(lower, "safari/"):
+		browser = "Safari"
+	}
+
+	os := ""
+	switch {
+	case strings.Contains(lower, "android"):
+		os = "Android"
+	case strings.Contains(lower, "iphone"), strings.Contains(lower, "ipad"):
+		os = "iOS"
+	case strings.Contains(lower, "windows"):
+		os = "Windows"
+	case strings.Contains(lower, "mac os"):
+		os = "macOS"
+	case strings.Contains(lower, "cros"):
+		os = "ChromeOS"
+	case strings.Contains(lower, "linux"):
+		os = "Linux"
+	}
+
+	if os == "" {
+		return browser
+	}
+	return browser + " on " + os
+}
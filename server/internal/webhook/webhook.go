@@ -0,0 +1,165 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Delivery headers
+const (
+	HeaderSignature = "X-SilentEcho-Signature"
+	HeaderEvent     = "X-SilentEcho-Event"
+	HeaderDelivery  = "X-SilentEcho-Delivery"
+)
+
+// MaxAttempts before a delivery is marked failed
+const MaxAttempts = 8
+
+var ErrPrivateAddress = errors.New("webhook URL resolves to a private address")
+
+// NewSecret creates a signing secret for an endpoint
+func NewSecret() (string, error) {
+	var b [24]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b[:]), nil
+}
+
+// Sign returns the signature header value for body. Receivers recompute
+// hex(HMAC-SHA256(secret, "<t>.<body>")) and compare it with v1, rejecting
+// old timestamps to stop replays.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Backoff is the wait before retrying after the given failed attempt
+func Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := 30 * time.Second << uint(attempt-1)
+	if d > 6*time.Hour || d <= 0 {
+		d = 6 * time.Hour
+	}
+	return d
+}
+
+// BackoffTiers lists the waits after failed attempts 1 to n
+func BackoffTiers(n int) []time.Duration {
+	tiers := make([]time.Duration, 0, n)
+	for attempt := 1; attempt <= n; attempt++ {
+		tiers = append(tiers, Backoff(attempt))
+	}
+	return tiers
+}
+
+// ValidateURL checks a user supplied endpoint. Plain http and private hosts
+// are only allowed when allowPrivate is set, e.g. for local development.
+func ValidateURL(raw string, allowPrivate bool) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return errors.New("webhook URL is invalid")
+	}
+	if u.Scheme != "https" && !(allowPrivate && u.Scheme == "http") {
+		return errors.New("webhook URL must use https")
+	}
+	if allowPrivate {
+		return nil
+	}
+	if u.Hostname() == "localhost" {
+		return ErrPrivateAddress
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && isPrivate(ip) {
+		return ErrPrivateAddress
+	}
+	return nil
+}
+
+// NewClient returns an HTTP client for user supplied URLs. Unless
+// allowPrivate is set it refuses to connect to private addresses, checked at
+// dial time so DNS tricks cannot reach internal services.
+func NewClient(allowPrivate bool) *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	if !allowPrivate {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivate(ip) {
+				return ErrPrivateAddress
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.Proxy = nil
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+		// Redirects could point anywhere, receivers must answer directly
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Request is a single signed delivery
+type Request struct {
+	URL        string
+	Secret     string
+	DeliveryID string
+	EventType  string
+	Body       []byte
+}
+
+// Send posts a signed payload, any 2xx response counts as delivered
+func Send(ctx context.Context, client *http.Client, r Request) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.URL, bytes.NewReader(r.Body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "SilentEcho-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, r.EventType)
+	req.Header.Set(HeaderDelivery, r.DeliveryID)
+	req.Header.Set(HeaderSignature, Sign(r.Secret, time.Now().Unix(), r.Body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast()
+}
@@ -0,0 +1,96 @@
+package webpush
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTTL is how long a signed VAPID token stays valid, at most 24h
+const vapidTTL = 12 * time.Hour
+
+var ErrInvalidVAPIDKey = errors.New("invalid VAPID key")
+
+// VAPID identifies this server to push services (RFC 8292)
+type VAPID struct {
+	PublicKey string // uncompressed P-256 point, base64url
+	Subject   string // mailto: or https: contact for the push service
+	key       *ecdsa.PrivateKey
+}
+
+// GenerateVAPIDKeys returns a new key pair as base64url strings
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	priv, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(priv.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(priv.Bytes()), nil
+}
+
+// NewVAPID parses a base64url private key and checks it matches the public key
+func NewVAPID(publicKey, privateKey, subject string) (*VAPID, error) {
+	raw, err := decodeKey(privateKey)
+	if err != nil {
+		return nil, ErrInvalidVAPIDKey
+	}
+	priv, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, ErrInvalidVAPIDKey
+	}
+
+	pub := priv.PublicKey().Bytes()
+	if expected, err := decodeKey(publicKey); err != nil || string(expected) != string(pub) {
+		return nil, errors.New("VAPID public key does not match the private key")
+	}
+
+	key := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(pub[1:33]),
+			Y:     new(big.Int).SetBytes(pub[33:]),
+		},
+		D: new(big.Int).SetBytes(raw),
+	}
+	return &VAPID{
+		PublicKey: base64.RawURLEncoding.EncodeToString(pub),
+		Subject:   subject,
+		key:       key,
+	}, nil
+}
+
+// Authorization returns the Authorization header for a push endpoint
+func (v *VAPID) Authorization(endpoint string, now time.Time) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": u.Scheme + "://" + u.Host,
+		"exp": now.Add(vapidTTL).Unix(),
+		"sub": v.Subject,
+	})
+	signed, err := token.SignedString(v.key)
+	if err != nil {
+		return "", err
+	}
+	return "vapid t=" + signed + ", k=" + v.PublicKey, nil
+}
+
+// decodeKey accepts base64url with or without padding, as browsers and
+// key generators disagree on it
+func decodeKey(s string) ([]byte, error) {
+	if b, err := base64.RawURLEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
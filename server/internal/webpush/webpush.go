@@ -0,0 +1,177 @@
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// recordSize is the aes128gcm record size, the whole payload fits one record
+const recordSize = 4096
+
+// MaxPayloadSize keeps the encrypted body within what push services accept
+const MaxPayloadSize = 3000
+
+var (
+	// ErrGone means the subscription expired or was revoked and should be removed
+	ErrGone = errors.New("push subscription is gone")
+
+	ErrInvalidSubscription = errors.New("invalid push subscription keys")
+	ErrPayloadTooLarge     = errors.New("push payload is too large")
+)
+
+// Subscription is the browser PushSubscription, keys base64url encoded
+type Subscription struct {
+	Endpoint string
+	P256dh   string
+	Auth     string
+}
+
+// Validate checks the subscription keys decode to a P-256 point and a 16 byte secret
+func (s Subscription) Validate() error {
+	pub, err := decodeKey(s.P256dh)
+	if err != nil {
+		return ErrInvalidSubscription
+	}
+	if _, err := ecdh.P256().NewPublicKey(pub); err != nil {
+		return ErrInvalidSubscription
+	}
+	if auth, err := decodeKey(s.Auth); err != nil || len(auth) != 16 {
+		return ErrInvalidSubscription
+	}
+	return nil
+}
+
+// Encrypt seals payload for the subscription using aes128gcm (RFC 8291)
+func Encrypt(s Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > MaxPayloadSize {
+		return nil, ErrPayloadTooLarge
+	}
+
+	rawPub, err := decodeKey(s.P256dh)
+	if err != nil {
+		return nil, ErrInvalidSubscription
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(rawPub)
+	if err != nil {
+		return nil, ErrInvalidSubscription
+	}
+	authSecret, err := decodeKey(s.Auth)
+	if err != nil || len(authSecret) != 16 {
+		return nil, ErrInvalidSubscription
+	}
+
+	// A fresh sender key and salt per message
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encrypt(uaPublic, authSecret, asPrivate, salt, payload)
+}
+
+// encrypt is Encrypt with the sender key and salt chosen by the caller
+func encrypt(uaPublic *ecdh.PublicKey, authSecret []byte, asPrivate *ecdh.PrivateKey, salt, payload []byte) ([]byte, error) {
+	rawPub := uaPublic.Bytes()
+	shared, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(rawPub) + string(asPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt | record size | key id length | sender public key
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// Send encrypts and delivers payload. ttl is how long the push service may
+// hold the message for an offline device.
+func Send(ctx context.Context, client *http.Client, v *VAPID, s Subscription, payload []byte, ttl time.Duration) (int, error) {
+	body, err := Encrypt(s, payload)
+	if err != nil {
+		return 0, err
+	}
+	auth, err := v.Authorization(s.Endpoint, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", auth)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return resp.StatusCode, ErrGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return resp.StatusCode, fmt.Errorf("push service returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,69 @@
+package webpush
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"encoding/base64"
+	"testing"
+)
+
+func mustDecode(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatalf("decode %q: %v", s, err)
+	}
+	return b
+}
+
+// The worked example of RFC 8291 section 5
+func TestEncryptRFC8291(t *testing.T) {
+	uaPublic, err := ecdh.P256().NewPublicKey(mustDecode(t, "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	asPrivate, err := ecdh.P256().NewPrivateKey(mustDecode(t, "yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	authSecret := mustDecode(t, "BTBZMqHH6r4Tts7J_aSIgg")
+	salt := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlw")
+	plaintext := []byte("When I grow up, I want to be a watermelon")
+
+	got, err := encrypt(uaPublic, authSecret, asPrivate, salt, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := mustDecode(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN")
+	if !bytes.Equal(got, want) {
+		t.Fatalf("encrypt =\n%s\nwant\n%s", base64.RawURLEncoding.EncodeToString(got), base64.RawURLEncoding.EncodeToString(want))
+	}
+}
+
+func TestEncryptRejectsBadInput(t *testing.T) {
+	valid := Subscription{
+		Endpoint: "https://push.example.com/abc",
+		P256dh:   "BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4",
+		Auth:     "BTBZMqHH6r4Tts7J_aSIgg",
+	}
+	if _, err := Encrypt(valid, []byte("hello")); err != nil {
+		t.Fatalf("valid subscription: %v", err)
+	}
+
+	badKey := valid
+	badKey.P256dh = "AAAA"
+	if _, err := Encrypt(badKey, []byte("hello")); err != ErrInvalidSubscription {
+		t.Errorf("bad public key: got %v", err)
+	}
+
+	badAuth := valid
+	badAuth.Auth = "c2hvcnQ"
+	if _, err := Encrypt(badAuth, []byte("hello")); err != ErrInvalidSubscription {
+		t.Errorf("short auth secret: got %v", err)
+	}
+
+	if _, err := Encrypt(valid, make([]byte, MaxPayloadSize+1)); err != ErrPayloadTooLarge {
+		t.Errorf("oversized payload: got %v", err)
+	}
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/rohits-web03/SilentEcho/server/internal/attachments"
+	"github.com/rohits-web03/SilentEcho/server/internal/blobstore"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+)
+
+// abandonedUploadAge is how long an unfinished upload is kept
+const abandonedUploadAge = 24 * time.Hour
+
+// PurgeAttachments deletes the blobs of attachments whose note was deleted,
+// revoked, destroyed, expired or burned, and of uploads that were never finished. Burned
+// notes keep theirs while download links handed out on the last read work.
+func PurgeAttachments(store blobstore.Store, now time.Time) (int, error) {
+	var dead []models.Attachment
+	err := repositories.DB.
+		Joins("LEFT JOIN notes ON notes.id = attachments.note_id").
+		Where("attachments.note_id IS NULL").
+		Or("attachments.status = ? AND attachments.created_at < ?", models.AttachmentUploading, now.Add(-abandonedUploadAge)).
+		Or("notes.revoked_at IS NOT NULL OR notes.destroyed_at IS NOT NULL").
+		Or("notes.expires_at <= ?", now).
+		Or(`notes.max_views IS NOT NULL AND notes.view_count >= notes.max_views AND NOT EXISTS (
+			SELECT 1 FROM note_views WHERE note_views.note_id = notes.id AND note_views.viewed_at > ?)`,
+			now.Add(-config.Envs.AttachmentDownloadTTL)).
+		Limit(200).Find(&dead).Error
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, a := range dead {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		err := attachments.DeleteChunks(ctx, store, a.ID, a.ChunkCount)
+		cancel()
+		if err != nil {
+			return purged, err
+		}
+		if err := repositories.DB.Delete(&models.Attachment{}, "id = ?", a.ID).Error; err != nil {
+			return purged, err
+		}
+		purged++
+	}
+	return purged, nil
+}
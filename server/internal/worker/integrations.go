@@ -0,0 +1,145 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/integrations"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+)
+
+// maxIntegrationAttempts bounds retries, chat notifications go stale quickly
+const maxIntegrationAttempts = 5
+
+// IntegrationRetryDelays are the delay queues retries of integrations wait in
+func IntegrationRetryDelays() []time.Duration {
+	return webhook.BackoffTiers(maxIntegrationAttempts - 1)
+}
+
+// IntegrationJob forwards one message to one integration
+type IntegrationJob struct {
+	IntegrationID uuid.UUID          `json:"integrationId"`
+	Message       events.MessageData `json:"message"`
+	Attempt       int                `json:"attempt"`
+}
+
+// ProcessIntegrationEvent fans a message.created event out into one job per
+// active integration of the recipient
+func ProcessIntegrationEvent(rmq *queue.RabbitMQ, d amqp.Delivery) {
+	var event events.Event
+	var data events.MessageData
+	if err := json.Unmarshal(d.Body, &event); err != nil || json.Unmarshal(event.Data, &data) != nil {
+		log.Printf("Invalid event format: %v", err)
+		d.Nack(false, false) // reject, don’t requeue
+		return
+	}
+
+	if !shouldNotify(data) {
+		d.Ack(false)
+		return
+	}
+
+	var ids []uuid.UUID
+	err := repositories.DB.Model(&models.Integration{}).
+		Where("user_id = ? AND is_active = ?", event.UserID, true).
+		Pluck("id", &ids).Error
+	if err != nil {
+		log.Printf("Failed to load integrations for %s: %v", event.UserID, err)
+		d.Nack(false, true) // retry later
+		return
+	}
+
+	for _, id := range ids {
+		body, err := json.Marshal(IntegrationJob{IntegrationID: id, Message: data})
+		if err == nil {
+			err = rmq.Publish(config.Envs.INTEGRATIONS_DELIVERY_QUEUE, body)
+		}
+		if err != nil {
+			log.Printf("Failed to enqueue integration %s: %v", id, err)
+		}
+	}
+
+	d.Ack(false)
+}
+
+// ProcessIntegrationJob sends a message to its integration, retrying with
+// backoff a few times before giving up
+func ProcessIntegrationJob(rmq *queue.RabbitMQ, client *http.Client, d amqp.Delivery) {
+	var job IntegrationJob
+	if err := json.Unmarshal(d.Body, &job); err != nil {
+		log.Printf("Invalid integration job: %v", err)
+		d.Nack(false, false)
+		return
+	}
+
+	var integration models.Integration
+	if err := repositories.DB.Where("id = ? AND is_active = ?", job.IntegrationID, true).First(&integration).Error; err != nil {
+		// Deleted or disabled since the message arrived
+		d.Ack(false)
+		return
+	}
+
+	err := SendIntegration(client, integration, IntegrationMessage(job.Message, integration.HideContent))
+	if err == nil {
+		repositories.DB.Model(&integration).Updates(map[string]interface{}{
+			"last_error":        "",
+			"last_delivered_at": time.Now(),
+		})
+		d.Ack(false)
+		return
+	}
+
+	log.Printf("Integration %s failed (attempt %d): %v", integration.ID, job.Attempt+1, err)
+	repositories.DB.Model(&integration).Update("last_error", err.Error())
+
+	job.Attempt++
+	if job.Attempt < maxIntegrationAttempts {
+		body, _ := json.Marshal(job)
+		if err := rmq.PublishDelayed(config.Envs.INTEGRATIONS_RETRY_QUEUE, body, webhook.Backoff(job.Attempt)); err != nil {
+			log.Printf("Failed to schedule retry of integration %s: %v", integration.ID, err)
+		}
+	}
+	d.Ack(false)
+}
+
+// SendIntegration delivers a message to an integration right away
+func SendIntegration(client *http.Client, integration models.Integration, m integrations.Message) error {
+	notifier, err := integrations.New(integration, client)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return notifier.Notify(ctx, m)
+}
+
+// IntegrationMessage formats a stored message for chat
+func IntegrationMessage(data events.MessageData, hideContent bool) integrations.Message {
+	m := integrations.Message{
+		ID:          data.ID.String(),
+		IsEncrypted: data.IsEncrypted,
+		CreatedAt:   data.CreatedAt,
+		URL:         dashboardURL(),
+	}
+	if !hideContent && !data.IsEncrypted {
+		m.Content = data.Content
+	}
+	if data.PromptID != nil {
+		var prompt models.Prompt
+		if repositories.DB.Select("title").Where("id = ?", *data.PromptID).First(&prompt).Error == nil {
+			m.PromptTitle = prompt.Title
+		}
+	}
+	return m
+}
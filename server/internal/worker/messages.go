@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+)
+
+// shouldNotify reports whether a message.created event may leave the app
+// through emails, pushes, chat integrations or webhooks. Quarantined
+// messages wait for the owner's review before anything is sent about them.
+func shouldNotify(data events.MessageData) bool {
+	return data.Folder == models.FolderInbox
+}
@@ -0,0 +1,183 @@
+package worker
+
+import (
+	"encoding/json"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/notify"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/utils"
+)
+
+// instantCooldown stops a burst of messages from turning into a burst of emails
+const instantCooldown = time.Minute
+
+// EnqueueEmail hands a job to the email worker
+func EnqueueEmail(rmq *queue.RabbitMQ, job EmailJob) error {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	return rmq.Publish(config.Envs.EMAIL_QUEUE, body)
+}
+
+// ProcessMessageEvent sends an instant notification for a message.created event
+func ProcessMessageEvent(rmq *queue.RabbitMQ, d amqp.Delivery) {
+	var event events.Event
+	var data events.MessageData
+	if err := json.Unmarshal(d.Body, &event); err != nil || json.Unmarshal(event.Data, &data) != nil {
+		log.Printf("Invalid event format: %v", err)
+		d.Nack(false, false) // reject, don’t requeue
+		return
+	}
+
+	if !shouldNotify(data) {
+		d.Ack(false)
+		return
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("id = ?", event.UserID).First(&user).Error; err != nil {
+		log.Printf("Failed to load user %s: %v", event.UserID, err)
+		d.Ack(false)
+		return
+	}
+
+	now := time.Now()
+	if user.NotifyMode != notify.ModeInstant || (user.LastNotifiedAt != nil && now.Sub(*user.LastNotifiedAt) < instantCooldown) {
+		d.Ack(false)
+		return
+	}
+
+	if !claimNotification(user, now) {
+		d.Ack(false)
+		return
+	}
+
+	subject, plain, html := utils.NewMessageEmail(user.Username, dashboardURL(), unsubscribeURL(user.ID))
+	if err := EnqueueEmail(rmq, EmailJob{To: user.Email, Subject: subject, PlainBody: plain, HTMLBody: html}); err != nil {
+		log.Printf("Failed to enqueue notification for %s: %v", user.ID, err)
+		d.Nack(false, true) // retry later
+		return
+	}
+
+	d.Ack(false)
+}
+
+// ProcessNoteViewEvent emails the owner of a note about its first read, if
+// they asked for it when sharing the note
+func ProcessNoteViewEvent(rmq *queue.RabbitMQ, d amqp.Delivery) {
+	var event events.Event
+	var data events.NoteData
+	if err := json.Unmarshal(d.Body, &event); err != nil || json.Unmarshal(event.Data, &data) != nil {
+		log.Printf("Invalid event format: %v", err)
+		d.Nack(false, false) // reject, don’t requeue
+		return
+	}
+
+	var note models.Note
+	if err := repositories.DB.Where("id = ?", data.ID).First(&note).Error; err != nil || !note.NotifyOnView || note.UserID == nil {
+		d.Ack(false)
+		return
+	}
+
+	var user models.User
+	if err := repositories.DB.Where("id = ?", *note.UserID).First(&user).Error; err != nil {
+		log.Printf("Failed to load user %s: %v", *note.UserID, err)
+		d.Ack(false)
+		return
+	}
+
+	viewedAt := event.CreatedAt
+	if data.FirstViewedAt != nil {
+		viewedAt = *data.FirstViewedAt
+	}
+
+	subject, plain, html := utils.NoteViewedEmail(user.Username, viewedAt.UTC().Format("2 Jan 2006 15:04 MST"), notesURL())
+	if err := EnqueueEmail(rmq, EmailJob{To: user.Email, Subject: subject, PlainBody: plain, HTMLBody: html}); err != nil {
+		log.Printf("Failed to enqueue view receipt for note %s: %v", note.ID, err)
+		d.Nack(false, true) // retry later
+		return
+	}
+
+	d.Ack(false)
+}
+
+// SendDigests emails a summary to every user on mode whose digest is due
+func SendDigests(rmq *queue.RabbitMQ, mode string, now time.Time) error {
+	period := notify.DigestPeriod(mode)
+
+	var users []models.User
+	err := repositories.DB.
+		Where("notify_mode = ? AND is_verified = ?", mode, true).
+		Where("last_notified_at IS NULL OR last_notified_at <= ?", now.Add(-period)).
+		Find(&users).Error
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		since := now.Add(-period)
+		if user.LastNotifiedAt != nil {
+			since = *user.LastNotifiedAt
+		}
+
+		var count int64
+		err := repositories.DB.Model(&models.Message{}).
+			Where("user_id = ? AND folder = ? AND created_at > ? AND created_at <= ?", user.ID, models.FolderInbox, since, now).
+			Count(&count).Error
+		if err != nil {
+			log.Printf("Failed to count messages for %s: %v", user.ID, err)
+			continue
+		}
+
+		// Claiming first means a second worker skips this window, and an empty
+		// window still moves on so the next digest only covers new messages
+		if !claimNotification(user, now) || count == 0 {
+			continue
+		}
+
+		subject, plain, html := utils.DigestEmail(user.Username, count, mode, dashboardURL(), unsubscribeURL(user.ID))
+		if err := EnqueueEmail(rmq, EmailJob{To: user.Email, Subject: subject, PlainBody: plain, HTMLBody: html}); err != nil {
+			log.Printf("Failed to enqueue digest for %s: %v", user.ID, err)
+		}
+	}
+	return nil
+}
+
+// claimNotification moves LastNotifiedAt forward unless another worker already did
+func claimNotification(user models.User, now time.Time) bool {
+	result := repositories.DB.Model(&models.User{}).
+		Where("id = ? AND last_notified_at IS NOT DISTINCT FROM ?", user.ID, user.LastNotifiedAt).
+		UpdateColumn("last_notified_at", now)
+	if result.Error != nil {
+		log.Printf("Failed to update notification time for %s: %v", user.ID, result.Error)
+		return false
+	}
+	return result.RowsAffected == 1
+}
+
+func dashboardURL() string {
+	return config.Envs.AppURL + "/dashboard/messages"
+}
+
+func notesURL() string {
+	return config.Envs.AppURL + "/dashboard/notes"
+}
+
+// unsubscribeKey signs unsubscribe links. The API verifies them, so both
+// processes need the same TOKEN_SECRET.
+var unsubscribeKey = config.TokenKey("unsubscribe")
+
+func unsubscribeURL(userID uuid.UUID) string {
+	token := notify.UnsubscribeToken(unsubscribeKey, userID, time.Now().Add(config.Envs.UnsubscribeLinkTTL))
+	return config.Envs.APIURL + "/api/notifications/unsubscribe?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,84 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webpush"
+)
+
+// pushTTL is how long push services keep a notification for offline devices
+const pushTTL = 24 * time.Hour
+
+// PushPayload is what the service worker receives, it never carries message
+// content since lock screens show it to anyone nearby
+type PushPayload struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	URL       string `json:"url"`
+	MessageID string `json:"messageId"`
+}
+
+// ProcessPushEvent sends a message.created event to every browser the
+// recipient registered, dropping subscriptions the push service reports gone
+func ProcessPushEvent(client *http.Client, vapid *webpush.VAPID, d amqp.Delivery) {
+	var event events.Event
+	var data events.MessageData
+	if err := json.Unmarshal(d.Body, &event); err != nil || json.Unmarshal(event.Data, &data) != nil {
+		log.Printf("Invalid event format: %v", err)
+		d.Nack(false, false) // reject, don’t requeue
+		return
+	}
+
+	if !shouldNotify(data) {
+		d.Ack(false)
+		return
+	}
+
+	var subs []models.PushSubscription
+	if err := repositories.DB.Where("user_id = ?", event.UserID).Find(&subs).Error; err != nil {
+		log.Printf("Failed to load push subscriptions for %s: %v", event.UserID, err)
+		d.Nack(false, true) // retry later
+		return
+	}
+	if len(subs) == 0 {
+		d.Ack(false)
+		return
+	}
+
+	payload, _ := json.Marshal(PushPayload{
+		Title:     "New anonymous message",
+		Body:      "Someone sent you a message on SilentEcho",
+		URL:       dashboardURL(),
+		MessageID: data.ID.String(),
+	})
+
+	for _, sub := range subs {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		_, err := webpush.Send(ctx, client, vapid, webpush.Subscription{
+			Endpoint: sub.Endpoint,
+			P256dh:   sub.P256dh,
+			Auth:     sub.Auth,
+		}, payload, pushTTL)
+		cancel()
+
+		switch {
+		case errors.Is(err, webpush.ErrGone), errors.Is(err, webpush.ErrInvalidSubscription):
+			repositories.DB.Delete(&models.PushSubscription{}, "id = ?", sub.ID)
+		case err != nil:
+			log.Printf("Push to subscription %s failed: %v", sub.ID, err)
+		default:
+			repositories.DB.Model(&sub).Update("last_used_at", time.Now())
+		}
+	}
+
+	d.Ack(false)
+}
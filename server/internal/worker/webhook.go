@@ -0,0 +1,263 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/rohits-web03/SilentEcho/server/internal/config"
+	"github.com/rohits-web03/SilentEcho/server/internal/events"
+	"github.com/rohits-web03/SilentEcho/server/internal/models"
+	"github.com/rohits-web03/SilentEcho/server/internal/queue"
+	"github.com/rohits-web03/SilentEcho/server/internal/repositories"
+	"github.com/rohits-web03/SilentEcho/server/internal/webhook"
+)
+
+// staleDeliveryAge is how long a pending delivery may sit past its attempt
+// time before it is assumed lost and queued again
+const staleDeliveryAge = 5 * time.Minute
+
+// DeliveryJob asks the webhook worker to attempt one delivery
+type DeliveryJob struct {
+	DeliveryID uuid.UUID `json:"deliveryId"`
+}
+
+// WebhookPayload is the JSON body receivers get
+type WebhookPayload struct {
+	ID        uuid.UUID       `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookRetryDelays are the delay queues retries of deliveries wait in
+func WebhookRetryDelays() []time.Duration {
+	return webhook.BackoffTiers(webhook.MaxAttempts - 1)
+}
+
+// EnqueueDelivery queues a delivery for an immediate attempt
+func EnqueueDelivery(rmq *queue.RabbitMQ, deliveryID uuid.UUID) error {
+	body, err := json.Marshal(DeliveryJob{DeliveryID: deliveryID})
+	if err != nil {
+		return err
+	}
+	return rmq.Publish(config.Envs.WEBHOOK_DELIVERY_QUEUE, body)
+}
+
+// ProcessWebhookEvent fans an event out into one delivery per subscribed endpoint
+func ProcessWebhookEvent(rmq *queue.RabbitMQ, d amqp.Delivery) {
+	var event events.Event
+	if err := json.Unmarshal(d.Body, &event); err != nil {
+		log.Printf("Invalid event format: %v", err)
+		d.Nack(false, false) // reject, don’t requeue
+		return
+	}
+
+	if event.Type == events.MessageCreated {
+		var data events.MessageData
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			log.Printf("Invalid message event data: %v", err)
+			d.Nack(false, false)
+			return
+		}
+		if !shouldNotify(data) {
+			d.Ack(false)
+			return
+		}
+	}
+
+	var hooks []models.Webhook
+	if err := repositories.DB.Where("user_id = ? AND is_active = ?", event.UserID, true).Find(&hooks).Error; err != nil {
+		log.Printf("Failed to load webhooks for %s: %v", event.UserID, err)
+		d.Nack(false, true) // retry later
+		return
+	}
+
+	payload, err := json.Marshal(WebhookPayload{ID: event.ID, Type: event.Type, CreatedAt: event.CreatedAt, Data: event.Data})
+	if err != nil {
+		d.Nack(false, false)
+		return
+	}
+	sealed, err := models.SealWebhookPayload(repositories.DB, event.UserID, string(payload))
+	if err != nil {
+		log.Printf("Failed to seal payload of event %s: %v", event.ID, err)
+		d.Nack(false, true) // retry later
+		return
+	}
+
+	now := time.Now()
+	for _, hook := range hooks {
+		if !hook.Subscribes(event.Type) {
+			continue
+		}
+
+		delivery := models.WebhookDelivery{
+			WebhookID:     hook.ID,
+			EventID:       event.ID,
+			EventType:     event.Type,
+			Payload:       sealed,
+			Status:        models.DeliveryPending,
+			NextAttemptAt: &now,
+		}
+		if err := repositories.DB.Create(&delivery).Error; err != nil {
+			log.Printf("Failed to record delivery to webhook %s: %v", hook.ID, err)
+			continue
+		}
+		// A lost job is picked up again by RequeueStaleDeliveries
+		if err := EnqueueDelivery(rmq, delivery.ID); err != nil {
+			log.Printf("Failed to enqueue delivery %s: %v", delivery.ID, err)
+		}
+	}
+
+	d.Ack(false)
+}
+
+// ProcessDelivery attempts one webhook delivery and schedules a retry with
+// exponential backoff when it fails
+func ProcessDelivery(rmq *queue.RabbitMQ, client *http.Client, d amqp.Delivery) {
+	var job DeliveryJob
+	if err := json.Unmarshal(d.Body, &job); err != nil {
+		log.Printf("Invalid delivery job: %v", err)
+		d.Nack(false, false)
+		return
+	}
+
+	var delivery models.WebhookDelivery
+	if err := repositories.DB.Preload("Webhook").Where("id = ?", job.DeliveryID).First(&delivery).Error; err != nil {
+		// Deleted along with its webhook
+		d.Ack(false)
+		return
+	}
+	if delivery.Status != models.DeliveryPending {
+		d.Ack(false)
+		return
+	}
+	// A copy queued again before its retry is due, the delayed one sends it
+	if delivery.NextAttemptAt != nil && delivery.NextAttemptAt.After(time.Now()) {
+		d.Ack(false)
+		return
+	}
+
+	updates := map[string]interface{}{"attempts": delivery.Attempts + 1}
+	var retryDelay time.Duration
+	payload, openErr := models.OpenWebhookPayload(repositories.DB, delivery.Webhook.UserID, delivery.Payload)
+	switch {
+	case !delivery.Webhook.IsActive:
+		updates["status"] = models.DeliveryFailed
+		updates["last_error"] = "webhook is disabled"
+		updates["next_attempt_at"] = nil
+	case openErr != nil:
+		log.Printf("Failed to open payload of delivery %s: %v", delivery.ID, openErr)
+		updates["status"] = models.DeliveryFailed
+		updates["last_error"] = "payload could not be decrypted"
+		updates["next_attempt_at"] = nil
+	default:
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		code, err := webhook.Send(ctx, client, webhook.Request{
+			URL:        delivery.Webhook.URL,
+			Secret:     delivery.Webhook.Secret,
+			DeliveryID: delivery.ID.String(),
+			EventType:  delivery.EventType,
+			Body:       []byte(payload),
+		})
+		cancel()
+
+		now := time.Now()
+		updates["last_status_code"] = code
+		switch {
+		case err == nil:
+			updates["status"] = models.DeliverySucceeded
+			updates["delivered_at"] = now
+			updates["last_error"] = ""
+			updates["next_attempt_at"] = nil
+		case delivery.Attempts+1 >= webhook.MaxAttempts:
+			updates["status"] = models.DeliveryFailed
+			updates["last_error"] = err.Error()
+			updates["next_attempt_at"] = nil
+		default:
+			updates["last_error"] = err.Error()
+			retryDelay = webhook.Backoff(delivery.Attempts + 1)
+			updates["next_attempt_at"] = now.Add(retryDelay)
+		}
+	}
+
+	// Only the worker that still sees the old attempt count records the result
+	result := repositories.DB.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND attempts = ?", delivery.ID, delivery.Attempts).
+		Updates(updates)
+	if result.Error != nil {
+		log.Printf("Failed to record delivery %s: %v", delivery.ID, result.Error)
+		d.Nack(false, true)
+		return
+	}
+
+	if retryDelay > 0 && result.RowsAffected == 1 {
+		body, _ := json.Marshal(job)
+		if err := rmq.PublishDelayed(config.Envs.WEBHOOK_RETRY_QUEUE, body, retryDelay); err != nil {
+			log.Printf("Failed to schedule retry of delivery %s: %v", delivery.ID, err)
+		}
+	}
+
+	d.Ack(false)
+}
+
+// RequeueStaleDeliveries queues pending deliveries whose job was lost
+func RequeueStaleDeliveries(rmq *queue.RabbitMQ, now time.Time) error {
+	var deliveries []models.WebhookDelivery
+	err := repositories.DB.Select("id").
+		Where("status = ? AND next_attempt_at < ?", models.DeliveryPending, now.Add(-staleDeliveryAge)).
+		Limit(500).Find(&deliveries).Error
+	if err != nil {
+		return err
+	}
+
+	for _, delivery := range deliveries {
+		if err := EnqueueDelivery(rmq, delivery.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// PruneWebhookDeliveries deletes finished deliveries older than the
+// configured retention, payloads included
+func PruneWebhookDeliveries(now time.Time) (int64, error) {
+	result := repositories.DB.
+		Where("status <> ? AND created_at < ?", models.DeliveryPending, now.Add(-config.Envs.WebhookDeliveryRetention)).
+		Delete(&models.WebhookDelivery{})
+	return result.RowsAffected, result.Error
+}
+
+// AnnounceExpiredNotes publishes note.expired once for every owned note past
+// its expiry, anonymous notes have nobody to tell
+func AnnounceExpiredNotes(publisher *events.Publisher, now time.Time) error {
+	var notes []models.Note
+	err := repositories.DB.
+		Where("expires_at <= ? AND expiry_announced = ? AND user_id IS NOT NULL", now, false).
+		Limit(500).Find(&notes).Error
+	if err != nil {
+		return err
+	}
+
+	for _, note := range notes {
+		result := repositories.DB.Model(&models.Note{}).
+			Where("id = ? AND expiry_announced = ?", note.ID, false).
+			UpdateColumn("expiry_announced", true)
+		if result.Error != nil || result.RowsAffected == 0 {
+			continue
+		}
+
+		event, err := events.New(events.NoteExpired, *note.UserID, events.NewNoteData(note))
+		if err == nil {
+			err = publisher.Publish(event)
+		}
+		if err != nil {
+			log.Printf("Failed to publish %s for note %s: %v", events.NoteExpired, note.ID, err)
+		}
+	}
+	return nil
+}
@@ -1,3 +1,8 @@
 #!/bin/sh
 ./server &   # start server in background
+./notifier & # new message notifications and digests
+./webhooks & # outgoing webhook deliveries
+./integrations & # Slack, Discord and Matrix forwarding
+./push &     # Web Push notifications
+./cleanup &  # purges attachments of burned and expired notes
 ./worker     # run worker in foreground (keeps container alive)
@@ -7,7 +7,7 @@ import { useToast } from '@/components/ui/use-toast';
 import { FileText, Loader2, PlusCircle, RefreshCcw } from 'lucide-react';
 import { AxiosError } from 'axios';
 import React, { useCallback, useEffect, useState } from 'react';
-import { Note } from '@/types';
+import { NoteSummary, Page } from '@/types';
 import { ApiResponse } from '@/types/ApiResponse';
 import { useAuth } from '@/hooks/useAuth';
 import { NoteCard } from '@/components/NoteCard';
@@ -15,23 +15,31 @@ import CreateNoteDialog from '@/components/CreateNoteDialog';
 import { motion } from 'framer-motion';
 import { goapi } from '@/lib/utils';
 
+const NOTES_PAGE_SIZE = 20;
+
 export default function CipherNotesDashboard() {
-    const [notes, setNotes] = useState<Note[]>([]);
+    const [notes, setNotes] = useState<NoteSummary[]>([]);
+    const [total, setTotal] = useState(0);
+    const [page, setPage] = useState(1);
     const [isLoading, setIsLoading] = useState(false);
     const [showDialog, setShowDialog] = useState(false);
     const { toast } = useToast();
 
     const { user } = useAuth();
 
-    const fetchNotes = useCallback(async () => {
+    // Page 1 replaces the list, later pages are appended to it
+    const fetchNotes = useCallback(async (pageToLoad = 1) => {
         if (!user) return;
 
         setIsLoading(true);
         try {
-            const response = await goapi.get<ApiResponse<Note[]>>(`/api/notes/user/${user.id}`);
-            const { data: apiData } = response;
-            // console.log(apiData);
-            setNotes(apiData.data || []);
+            const response = await goapi.get<ApiResponse<Page<NoteSummary>>>(`/api/notes/user/${user.id}`, {
+                params: { page: pageToLoad, limit: NOTES_PAGE_SIZE },
+            });
+            const items = response.data.data?.items ?? [];
+            setNotes((prev) => (pageToLoad === 1 ? items : [...prev, ...items]));
+            setTotal(response.data.data?.total ?? 0);
+            setPage(pageToLoad);
         } catch (error) {
             const axiosError = error as AxiosError<ApiResponse<unknown>>;
             toast({
@@ -51,6 +59,7 @@ export default function CipherNotesDashboard() {
 
     const handleDeleteNote = (noteId: string) => {
         setNotes(notes.filter((note) => note.id !== noteId));
+        setTotal((prev) => Math.max(prev - 1, 0));
     };
 
     if (!user) {
@@ -68,7 +77,7 @@ export default function CipherNotesDashboard() {
                     <Button
                         variant="outline"
                         size="sm"
-                        onClick={fetchNotes}
+                        onClick={() => fetchNotes(1)}
                         disabled={isLoading}
                     >
                         {isLoading ? (
@@ -92,21 +101,31 @@ export default function CipherNotesDashboard() {
 
             <div className="space-y-6">
                 {notes.length > 0 ? (
-                    <div className="grid gap-4 md:grid-cols-2 lg:grid-cols-3">
-                        {notes.map((note, index) => (
-                            <motion.div
-                                key={note.slug}
-                                initial={{ opacity: 0, y: 20 }}
-                                animate={{ opacity: 1, y: 0 }}
-                                transition={{ delay: index * 0.05 }}
-                            >
-                                <NoteCard
-                                    note={note}
-                                    onDelete={handleDeleteNote}
-                                />
-                            </motion.div>
-                        ))}
-                    </div>
+                    <>
+                        <div className="grid gap-4 md:grid-cols-2 lg:grid-cols-3">
+                            {notes.map((note, index) => (
+                                <motion.div
+                                    key={note.slug}
+                                    initial={{ opacity: 0, y: 20 }}
+                                    animate={{ opacity: 1, y: 0 }}
+                                    transition={{ delay: index * 0.05 }}
+                                >
+                                    <NoteCard
+                                        note={note}
+                                        onDelete={handleDeleteNote}
+                                    />
+                                </motion.div>
+                            ))}
+                        </div>
+                        {notes.length < total && (
+                            <div className="flex justify-center">
+                                <Button variant="outline" size="sm" onClick={() => fetchNotes(page + 1)} disabled={isLoading}>
+                                    {isLoading && <Loader2 className="h-4 w-4 animate-spin mr-2" />}
+                                    Load more
+                                </Button>
+                            </div>
+                        )}
+                    </>
                 ) : (
                     <Card className="border-dashed">
                         <CardContent className="flex flex-col items-center justify-center py-12 text-center">
@@ -126,7 +145,7 @@ export default function CipherNotesDashboard() {
                 userId={user.id}
                 open={showDialog}
                 onOpenChange={setShowDialog}
-                onNoteCreated={fetchNotes}
+                onNoteCreated={() => fetchNotes(1)}
             />
         </div>
     );
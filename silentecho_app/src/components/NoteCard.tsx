@@ -1,14 +1,14 @@
 'use client';
 
-import { Note } from '@/types';
+import { NoteSummary } from '@/types';
 import { Button } from '@/components/ui/button';
 import { Card, CardContent } from '@/components/ui/card';
-import { Trash, Link as LinkIcon, Clock } from 'lucide-react';
+import { Trash, Link as LinkIcon, Clock, Eye } from 'lucide-react';
 import axios from 'axios';
 import { useToast } from './ui/use-toast';
 
 interface NoteCardProps {
-    note: Note;
+    note: NoteSummary;
     onDelete: (noteId: string) => void;
 }
 
@@ -47,9 +47,15 @@ export function NoteCard({ note, onDelete }: NoteCardProps) {
     return (
         <Card className="p-4">
             <CardContent className="space-y-3">
-                <p className="text-muted-foreground break-all">
-                    <span className="font-medium">Cipher:</span> {note.ciphernote.slice(0, 100)}...
-                </p>
+                <div className="flex items-center justify-between gap-2">
+                    <p className="font-mono text-sm break-all">{note.slug}</p>
+                    <span className="text-xs uppercase tracking-wide text-muted-foreground">{note.status}</span>
+                </div>
+
+                <div className="flex items-center text-sm text-gray-500">
+                    <Eye className="h-4 w-4 mr-1" />
+                    {note.maxViews ? `${note.viewCount} of ${note.maxViews} views` : `${note.viewCount} views`}
+                </div>
 
                 {note.expiresAt && (
                     <div className="flex items-center text-sm text-gray-500">
@@ -34,3 +34,31 @@ export interface Note {
     user?: User;
 }
 
+export type NoteStatus = 'active' | 'revoked' | 'expired' | 'exhausted' | 'destroyed' | 'draft' | 'scheduled';
+
+// A note as listed on the dashboard, without its ciphertext
+export interface NoteSummary {
+    id: string; // uuid
+    slug: string;
+    title?: string; // encrypted by the client like the note
+    folderId?: string | null;
+    labels: string[];
+    status: NoteStatus;
+    createdAt: string; // ISO datetime string
+    expiresAt?: string | null;
+    maxViews?: number | null;
+    viewCount: number;
+    viewsRemaining?: number | null;
+    draft: boolean;
+    notBefore?: string | null;
+    gated: boolean;
+}
+
+// One page of a paginated list
+export interface Page<T> {
+    total: number;
+    page: number;
+    limit: number;
+    items: T[];
+}
+